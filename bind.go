@@ -0,0 +1,105 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Fills the exported fields of the struct pointed by `dst` from the path variables and the query parameters.
+// A field is looked up by its `path` or `query` tag, otherwise by its name (case-insensitive) among
+// the path variables then the query parameters. Supported kinds: string, bool, int*, uint*, float*.
+// A malformed value gives a `BadRequest()` error (answered with a `400`), an invalid `dst` or an unsupported field type
+// is a programming error (answered with a `500`).
+func (h *Http) Bind(dst interface{}) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("[Http#Bind] dst must be a non-nil pointer to a struct but was '%T'", dst)
+	}
+
+	query := h.queryValues()
+	structValue := dstValue.Elem()
+
+	for _, field := range schemaOf(structValue.Type()).fields {
+		var value string
+		var found bool
+		switch field.source {
+			case fieldSourcePath:
+				value, found = h.PathVariables[field.key]
+			case fieldSourceQuery:
+				value, found = query.Get(field.key), query.Has(field.key)
+			default:
+				if value, found = lookupFold(h.PathVariables, field.key); !found {
+					for name, values := range query {
+						if strings.EqualFold(name, field.key) && len(values) > 0 {
+							value, found = values[0], true
+							break
+						}
+					}
+				}
+		}
+
+		if !found {
+			continue
+		}
+
+		if err := setFieldValue(structValue.Field(field.index), value); err != nil {
+			if errors.Is(err, errUnsupportedFieldType) {
+				return fmt.Errorf("[Http#Bind] '%s' %w", field.name, err)
+			}
+			return BadRequest(fmt.Sprintf("'%s' %s", field.name, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+func lookupFold(values map[string]string, key string) (string, bool) {
+	for name, value := range values {
+		if strings.EqualFold(name, key) {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+var errUnsupportedFieldType = errors.New("has an unsupported type")
+
+// Converts the raw string into the field's kind
+func setFieldValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Bool:
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("must be a boolean but was '%s'", value)
+			}
+			field.SetBool(parsed)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("must be an integer but was '%s'", value)
+			}
+			field.SetInt(parsed)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			parsed, err := strconv.ParseUint(value, 10, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("must be a positive integer but was '%s'", value)
+			}
+			field.SetUint(parsed)
+		case reflect.Float32, reflect.Float64:
+			parsed, err := strconv.ParseFloat(value, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("must be a number but was '%s'", value)
+			}
+			field.SetFloat(parsed)
+		default:
+			return fmt.Errorf("%w '%s'", errUnsupportedFieldType, field.Type())
+	}
+
+	return nil
+}
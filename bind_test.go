@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"testing"
+	"errors"
+	"strings"
+	"net/http"
+	"net/http/httptest"
+)
+
+type mockBindTarget struct {
+	ID int64 `path:"id"`
+	Limit int `query:"limit"`
+	Sort string
+	Verbose bool
+	ignored string
+}
+
+func TestHttpBind_when_nominal(t *testing.T) {
+	// GIVEN
+	h := &Http{
+		Request: httptest.NewRequest(http.MethodGet, "/users/42?limit=10&sort=name&verbose=true", nil),
+		PathVariables: map[string]string{"id": "42"}}
+	var target mockBindTarget
+
+	// WHEN
+	err := h.Bind(&target)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	expected := mockBindTarget{ID: 42, Limit: 10, Sort: "name", Verbose: true}
+	if target != expected {
+		t.Errorf("Actual: '%+v', expected: '%+v'", target, expected)
+	}
+}
+
+func TestHttpBind_when_absentValues(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/users", nil), PathVariables: map[string]string{}}
+	target := mockBindTarget{Limit: 20}
+
+	// WHEN
+	err := h.Bind(&target)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	if target.Limit != 20 {
+		t.Errorf("Actual: '%d', expected: '%d'", target.Limit, 20)
+	}
+}
+
+func TestHttpBind_when_error_malformedInteger(t *testing.T) {
+	// GIVEN
+	h := &Http{
+		Request: httptest.NewRequest(http.MethodGet, "/users/abc?limit=10", nil),
+		PathVariables: map[string]string{"id": "abc"}}
+	var target mockBindTarget
+
+	// WHEN
+	err := h.Bind(&target)
+
+	// THEN
+	var httpError *HTTPError
+	if !errors.As(err, &httpError) || httpError.Code != http.StatusBadRequest {
+		t.Errorf("Actual: '%v', expected: '%v'", err, BadRequest("'ID' must be an integer but was 'abc'"))
+	}
+}
+
+func TestHttpBind_when_dispatcherMalformedQueryParameter(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) (HttpResponse, error) {
+		var target mockBindTarget
+		if err := h.Bind(&target); err != nil {
+			return nil, err
+		}
+		return NoContentResponse(), nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/42?limit=ten", nil))
+
+	// THEN
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(recorder.Body.String(), "'Limit' must be an integer but was 'ten'") {
+		t.Errorf("Actual: '%s', expected the malformed field in the message", recorder.Body.String())
+	}
+}
+
+func TestHttpBind_when_error_notStructPointer(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/users", nil)}
+	var target mockBindTarget
+
+	// WHEN
+	err := h.Bind(target)
+
+	// THEN
+	if err == nil {
+		t.Errorf("Expected an error for a non-pointer target")
+	}
+}
+
+func BenchmarkHttpBind(b *testing.B) {
+	h := &Http{
+		Request: httptest.NewRequest(http.MethodGet, "/users/42?limit=10&sort=name&verbose=true", nil),
+		PathVariables: map[string]string{"id": "42"}}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var target mockBindTarget
+		h.Bind(&target)
+	}
+}
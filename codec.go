@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type MarshalFunc func(interface{}) ([]byte, error)
+type UnmarshalFunc func([]byte, interface{}) error
+
+// Content-Type => codec, users register only the codecs they need (ex: msgpack, protobuf)
+var codecs = struct {
+	sync.RWMutex
+	marshalers map[string]MarshalFunc
+	unmarshalers map[string]UnmarshalFunc
+}{
+	marshalers: map[string]MarshalFunc{
+		"application/json": json.Marshal,
+		"application/xml": xml.Marshal,
+	},
+	unmarshalers: map[string]UnmarshalFunc{
+		"application/json": json.Unmarshal,
+		"application/xml": xml.Unmarshal,
+		"application/x-www-form-urlencoded": unmarshalForm,
+	},
+}
+
+// Media type without parameters, in lower case. Ex: `Application/JSON; charset=utf-8` => `application/json`
+func toMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+// Registers the marshaler used by responses of this Content-Type (see `MarshaledResponse()`)
+func RegisterMarshaler(contentType string, marshaler MarshalFunc) {
+	if marshaler == nil {
+		panic("[RegisterMarshaler] marshaler must not be `nil`")
+	}
+
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.marshalers[toMediaType(contentType)] = marshaler
+}
+
+// Registers the unmarshaler used for request bodies of this Content-Type
+func RegisterUnmarshaler(contentType string, unmarshaler UnmarshalFunc) {
+	if unmarshaler == nil {
+		panic("[RegisterUnmarshaler] unmarshaler must not be `nil`")
+	}
+
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.unmarshalers[toMediaType(contentType)] = unmarshaler
+}
+
+// Registers both directions of a format (ex: MessagePack, CBOR): `marshaler` is used by `MarshaledResponse()` and
+// `NegotiatedResponse()`, `unmarshaler` for request bodies of this Content-Type
+func RegisterCodec(contentType string, marshaler MarshalFunc, unmarshaler UnmarshalFunc) {
+	if marshaler == nil || unmarshaler == nil {
+		panic("[RegisterCodec] marshaler and unmarshaler must not be `nil`")
+	}
+
+	mediaType := toMediaType(contentType)
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.marshalers[mediaType] = marshaler
+	codecs.unmarshalers[mediaType] = unmarshaler
+}
+
+// Returns nil if no marshaler is registered for the Content-Type
+func lookupMarshaler(contentType string) MarshalFunc {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	return codecs.marshalers[toMediaType(contentType)]
+}
+
+// Returns nil if no unmarshaler is registered for the Content-Type
+func lookupUnmarshaler(contentType string) UnmarshalFunc {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	return codecs.unmarshalers[toMediaType(contentType)]
+}
+
+// Response marshaled with the marshaler registered for the Content-Type (see `RegisterMarshaler()`)
+func MarshaledResponse(statusCode int, contentType string, responseBody interface{}) HttpResponse {
+	marshaler := lookupMarshaler(contentType)
+	if marshaler == nil {
+		panic(fmt.Sprintf("[MarshaledResponse] No marshaler registered for Content-Type '%s'", contentType))
+	}
+
+	return &ResponseWriter{
+		contentType: contentType,
+		statusCode: statusCode,
+		responseBody: responseBody,
+		marshal: marshaler}
+}
+
+// Content types having a marshaler: JSON first (the default), XML, then the registered ones sorted
+func marshaledContentTypes() []string {
+	codecs.RLock()
+	defer codecs.RUnlock()
+
+	contentTypes := []string{"application/json", "application/xml"}
+	registered := make([]string, 0, len(codecs.marshalers))
+	for contentType := range codecs.marshalers {
+		if contentType != "application/json" && contentType != "application/xml" {
+			registered = append(registered, contentType)
+		}
+	}
+	sort.Strings(registered)
+
+	return append(contentTypes, registered...)
+}
+
+// HTTP RESPONSE (NEGOTIATED)
+type NegotiatedResponseWriter struct {
+	statusCode int
+	responseBody interface{}
+	// Can be nil
+	customHeaders map[string]string
+}
+
+func (r *NegotiatedResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	contentType := negotiateContentType(request.Header.Get("Accept"), marshaledContentTypes())
+	if contentType == "" {
+		// Lenient, `Dispatcher.Produces` and `WithProduces()` answer `406 Not Acceptable` instead
+		contentType = "application/json"
+	}
+
+	// Caches must not serve a representation negotiated for another `Accept`
+	response.Header().Add("Vary", "Accept")
+
+	negotiated := &ResponseWriter{
+		contentType: contentType,
+		statusCode: r.statusCode,
+		responseBody: r.responseBody,
+		customHeaders: r.customHeaders,
+		marshal: lookupMarshaler(contentType)}
+	negotiated.write(response, request)
+}
+
+// Response marshaled in the format preferred by the request's `Accept` header among the registered marshalers,
+// JSON if the header is absent, `*/*` or allows none of them. `customHeaders` can be nil.
+func NegotiatedResponse(statusCode int, responseBody interface{}, customHeaders map[string]string) HttpResponse {
+	return &NegotiatedResponseWriter{
+		statusCode: statusCode,
+		responseBody: responseBody,
+		customHeaders: customHeaders}
+}
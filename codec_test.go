@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"testing"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// Fake "a=<int>" codec for `mockRequestBody`
+const mockContentType = "application/x-mock"
+
+func registerMockCodec() {
+	RegisterMarshaler(mockContentType, func(v interface{}) ([]byte, error) {
+		return []byte(fmt.Sprintf("a=%d", v.(*mockRequestBody).A)), nil
+	})
+
+	RegisterUnmarshaler(mockContentType, func(data []byte, v interface{}) error {
+		a, err := strconv.Atoi(strings.TrimPrefix(string(data), "a="))
+		v.(*mockRequestBody).A = a
+		return err
+	})
+}
+
+func TestRegisterCodec_when_nominal(t *testing.T) {
+	// GIVEN
+	const fakeContentType = "application/x-fake"
+	RegisterCodec(fakeContentType, func(v interface{}) ([]byte, error) {
+		return []byte("fake:" + strconv.Itoa(v.(*mockRequestBody).A)), nil
+	}, func(data []byte, v interface{}) error {
+		a, err := strconv.Atoi(strings.TrimPrefix(string(data), "fake:"))
+		v.(*mockRequestBody).A = a
+		return err
+	})
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return NegotiatedResponse(http.StatusOK, &mockRequestBody{A: body.A + 1}, nil)
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader("fake:41"))
+	request.Header.Set("Content-Type", fakeContentType)
+	request.Header.Set("Accept", fakeContentType)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Body.String() != "fake:42" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "fake:42")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != fakeContentType {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, fakeContentType)
+	}
+}
+
+func TestRegisterCodec_when_error_nil(t *testing.T) {
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Expected a panic for a nil unmarshaler")
+		}
+	}()
+
+	// WHEN
+	RegisterCodec("application/x-fake", func(v interface{}) ([]byte, error) { return nil, nil }, nil)
+}
+
+func TestMarshaledResponse_when_customMarshaler(t *testing.T) {
+	// GIVEN
+	registerMockCodec()
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	MarshaledResponse(200, mockContentType, &mockRequestBody{A: 42}).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Body.String() != "a=42" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "a=42")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != mockContentType {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, mockContentType)
+	}
+}
+
+func TestMarshaledResponse_when_error_noMarshaler(t *testing.T) {
+	// GIVEN
+	contentType := "application/x-unknown"
+
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Expected a panic for an unregistered Content-Type")
+		}
+	}()
+
+	// WHEN
+	MarshaledResponse(200, contentType, nil)
+}
+
+func TestUnmarshal_when_customUnmarshaler(t *testing.T) {
+	// GIVEN
+	registerMockCodec()
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return MarshaledResponse(200, mockContentType, &mockRequestBody{A: body.A * 2})
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader("a=21"))
+	request.Header.Set("Content-Type", mockContentType + "; charset=utf-8")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Body.String() != "a=42" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "a=42")
+	}
+}
+
+func TestUnmarshal_when_xmlWithCharset(t *testing.T) {
+	// GIVEN
+	var body struct {
+		A int `xml:"a"`
+	}
+
+	// WHEN
+	err := unmarshal("application/xml; charset=utf-8", []byte("<body><a>1</a></body>"), &body)
+
+	// THEN
+	if err != nil || body.A != 1 {
+		t.Errorf("Actual: '%d' '%v', expected: '%d'", body.A, err, 1)
+	}
+}
+
+func TestNegotiatedResponse_when_nominal(t *testing.T) {
+	registerMockCodec()
+	cases := map[string]struct {
+		expectedContentType string
+		expectedBody string
+	}{
+		"application/xml": {"application/xml", "<mockRequestBody><A>42</A></mockRequestBody>"},
+		"application/json": {"application/json", `{"a":42}`},
+		"": {"application/json", `{"a":42}`},
+		"*/*": {"application/json", `{"a":42}`},
+		"text/html, application/xml;q=0.9": {"application/xml", "<mockRequestBody><A>42</A></mockRequestBody>"},
+		mockContentType: {mockContentType, "a=42"},
+		"image/png": {"application/json", `{"a":42}`},
+	}
+
+	for accept, c := range cases {
+		// GIVEN
+		request := httptest.NewRequest(http.MethodGet, "/a", nil)
+		request.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		NegotiatedResponse(http.StatusOK, &mockRequestBody{A: 42}, map[string]string{"X-Custom": "1"}).write(recorder, request)
+
+		// THEN
+		if actual := recorder.Header().Get("Content-Type"); actual != c.expectedContentType {
+			t.Errorf("Actual: '%s' for '%s', expected: '%s'", actual, accept, c.expectedContentType)
+		}
+
+		if recorder.Body.String() != c.expectedBody {
+			t.Errorf("Actual: '%s' for '%s', expected: '%s'", recorder.Body.String(), accept, c.expectedBody)
+		}
+
+		if recorder.Header().Get("Vary") != "Accept" || recorder.Header().Get("X-Custom") != "1" {
+			t.Errorf("Actual: '%v' for '%s', expected: the 'Vary' and custom headers", recorder.Header(), accept)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/csv"
+)
+
+// RFC 4180: fields containing a comma, a quote or a line break are quoted, quotes are doubled, records end with CRLF
+func marshalCSV(rows interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.UseCRLF = true
+	if err := writer.WriteAll(rows.([][]string)); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Tabular data as `text/csv`, `customHeaders` can be nil. For a download, add a `Content-Disposition`
+// (ex: `map[string]string{"Content-Disposition": ContentDisposition("attachment", "report.csv")}`)
+func CsvResponse(statusCode int, rows [][]string, customHeaders map[string]string) HttpResponse {
+	return &ResponseWriter{
+		contentType: "text/csv",
+		statusCode: statusCode,
+		responseBody: rows,
+		customHeaders: customHeaders,
+		marshal: marshalCSV}
+}
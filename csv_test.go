@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestCsvResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	rows := [][]string{
+		{"name", "comment"},
+		{"Doe, Jane", `She said "hi"`},
+		{"multi", "line 1\nline 2"},
+	}
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	CsvResponse(http.StatusOK, rows, map[string]string{"Content-Disposition": ContentDisposition("attachment", "report.csv")}).write(recorder, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+	// THEN
+	expected := "name,comment\r\n\"Doe, Jane\",\"She said \"\"hi\"\"\"\r\nmulti,\"line 1\r\nline 2\"\r\n"
+	if recorder.Body.String() != expected {
+		t.Errorf("Actual: '%q', expected: '%q'", recorder.Body.String(), expected)
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/csv" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "text/csv")
+	}
+
+	if actual := recorder.Header().Get("Content-Disposition"); actual != `attachment; filename="report.csv"` {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, `attachment; filename="report.csv"`)
+	}
+}
+
+func TestCsvResponse_when_noRows(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	CsvResponse(http.StatusOK, nil, nil).write(recorder, httptest.NewRequest(http.MethodGet, "/report", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%d %q', expected: '%d %q'", recorder.Code, recorder.Body.String(), http.StatusOK, "")
+	}
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Debugging aid, describes how the routes registered for `httpMethod` and `path` (the registered path, ex: `/users/{id}`)
+// are matched: compiled regex, static lookup, host, path variables and request body. Empty if no route is registered.
+// Ex:
+//   GET /users/{id}
+//   Regex: ^/users/[a-zA-Z0-9_-]+$
+//   Static: false
+//   Host: *
+//   Path variables: id (segment 1)
+//   Request body: none
+func (dispatcher *Dispatcher) DescribeRoute(httpMethod string, path string) string {
+	descriptions := make([]string, 0)
+	for _, handler := range dispatcher.routes[httpMethod] {
+		if handler.GetPath() != path {
+			continue
+		}
+
+		var description strings.Builder
+		fmt.Fprintf(&description, "%s %s\n", httpMethod, path)
+		fmt.Fprintf(&description, "Regex: %s\n", dispatcher.regexPath(handler))
+		fmt.Fprintf(&description, "Static: %t\n", dispatcher.staticRoutes[httpMethod][path] == handler)
+
+		host := handler.GetHost()
+		if host == "" {
+			host = "*"
+		}
+		fmt.Fprintf(&description, "Host: %s\n", host)
+
+		pathVariables := make([]string, 0)
+		for _, pathVariable := range handler.GetPathVariableNames() {
+			if pathVariable.catchAll {
+				pathVariables = append(pathVariables, fmt.Sprintf("%s (segments %d+)", pathVariable.variableName, pathVariable.pathIndex))
+				continue
+			}
+			if pathVariable.variableType != "" {
+				pathVariables = append(pathVariables, fmt.Sprintf("%s:%s (segment %d)", pathVariable.variableName, pathVariable.variableType, pathVariable.pathIndex))
+				continue
+			}
+			pathVariables = append(pathVariables, fmt.Sprintf("%s (segment %d)", pathVariable.variableName, pathVariable.pathIndex))
+		}
+		if len(pathVariables) == 0 {
+			pathVariables = append(pathVariables, "none")
+		}
+		fmt.Fprintf(&description, "Path variables: %s\n", strings.Join(pathVariables, ", "))
+
+		if handler.HasRequestBody() {
+			fmt.Fprintf(&description, "Request body: %s", handler.GetRequestBodyType())
+		} else {
+			description.WriteString("Request body: none")
+		}
+
+		descriptions = append(descriptions, description.String())
+	}
+
+	return strings.Join(descriptions, "\n\n")
+}
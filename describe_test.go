@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"strings"
+)
+
+func TestDispatcherDescribeRoute_when_pathVariables(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, requestBody *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().PUT("/users/{id}/books/{book-id}", handler), nil)
+
+	// WHEN
+	actual := dispatcher.DescribeRoute(http.MethodPut, "/users/{id}/books/{book-id}")
+
+	// THEN
+	for _, expected := range []string{
+		"PUT /users/{id}/books/{book-id}",
+		"Regex: ^/users/[a-zA-Z0-9_-]+/books/[a-zA-Z0-9_-]+$",
+		"Static: false",
+		"Path variables: id (segment 1), book-id (segment 3)",
+		"Request body: rest.mockRequestBody"} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+		}
+	}
+}
+
+func TestDispatcherDescribeRoute_when_staticRouteAndCustomPattern(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler).GET("/users/{id}", handler), nil)
+	dispatcher.PathVariablePattern = "[0-9]+"
+	if err := dispatcher.Compile(); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	// WHEN
+	static := dispatcher.DescribeRoute(http.MethodGet, "/users")
+	dynamic := dispatcher.DescribeRoute(http.MethodGet, "/users/{id}")
+
+	// THEN
+	for actual, expected := range map[string]string{
+		static: "Static: true",
+		dynamic: "Regex: ^/users/(?P<id>(?:[0-9]+))$"} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+		}
+	}
+}
+
+func TestDispatcherDescribeRoute_when_noRoute(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes(), nil)
+
+	// WHEN
+	actual := dispatcher.DescribeRoute(http.MethodGet, "/users")
+
+	// THEN
+	if actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+}
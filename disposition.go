@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Characters sent as is in an RFC 5987 extended value, the others are percent-encoded
+func isAttrChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// Value of a `Content-Disposition` header for `FileResponse()`. Ex: `ContentDisposition("attachment", "résumé.pdf")` =>
+// `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`.
+// The quoted filename escapes `"` and `\`, non-ASCII filenames are also RFC 5987-encoded for the clients supporting it.
+func ContentDisposition(dispositionType string, filename string) string {
+	var fallback strings.Builder
+	asciiOnly := true
+	for _, r := range filename {
+		switch {
+			case r == '"' || r == '\\':
+				fallback.WriteByte('\\')
+				fallback.WriteRune(r)
+			case r < 0x20 || r == 0x7f || r > 0x7e:
+				fallback.WriteByte('_')
+				asciiOnly = false
+			default:
+				fallback.WriteRune(r)
+		}
+	}
+
+	disposition := dispositionType + `; filename="` + fallback.String() + `"`
+	if asciiOnly {
+		return disposition
+	}
+
+	var encoded strings.Builder
+	for i := 0; i < len(filename); i++ {
+		if c := filename[i]; isAttrChar(c) {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+
+	return disposition + "; filename*=UTF-8''" + encoded.String()
+}
@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"testing"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func TestContentDisposition_when_ascii(t *testing.T) {
+	// GIVEN
+	filename := "report 2018.pdf"
+
+	// WHEN
+	actual := ContentDisposition("attachment", filename)
+
+	// THEN
+	if expected := `attachment; filename="report 2018.pdf"`; actual != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+}
+
+func TestContentDisposition_when_quotesAndCommas(t *testing.T) {
+	// GIVEN
+	filename := `my "best", \final.csv`
+
+	// WHEN
+	actual := ContentDisposition("attachment", filename)
+
+	// THEN
+	if expected := `attachment; filename="my \"best\", \\final.csv"`; actual != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+
+	// Parsed back by a standard parser
+	if _, params, err := mime.ParseMediaType(actual); err != nil || params["filename"] != filename {
+		t.Errorf("Actual: '%v' (%v), expected: '%v'", params["filename"], err, filename)
+	}
+}
+
+func TestContentDisposition_when_utf8(t *testing.T) {
+	// GIVEN
+	filename := "résumé 履歴書.pdf"
+
+	// WHEN
+	actual := ContentDisposition("attachment", filename)
+
+	// THEN
+	expected := `attachment; filename="r_sum_ ___.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9%20%E5%B1%A5%E6%AD%B4%E6%9B%B8.pdf`
+	if actual != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+
+	// `filename*` takes precedence for the clients supporting it
+	if _, params, err := mime.ParseMediaType(actual); err != nil || params["filename"] != filename {
+		t.Errorf("Actual: '%v' (%v), expected: '%v'", params["filename"], err, filename)
+	}
+}
+
+func TestFileResponse_when_contentDisposition(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+	contentDisposition := ContentDisposition("attachment", "naïve.txt")
+
+	// WHEN
+	FileResponse(http.StatusOK, "text/plain", contentDisposition, 1, strings.NewReader("a")).write(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Disposition"); actual != contentDisposition {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, contentDisposition)
+	}
+}
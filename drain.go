@@ -0,0 +1,30 @@
+package rest
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// Number of requests currently being served
+func (dispatcher *Dispatcher) InFlight() int {
+	return int(atomic.LoadInt64(&dispatcher.inFlight))
+}
+
+// Marks the dispatcher as draining: `Readiness()` answers `503 Service Unavailable` so that load balancers stop
+// sending traffic, while requests keep being served. Call it some probe periods before `http.Server.Shutdown()`.
+func (dispatcher *Dispatcher) Drain() {
+	atomic.StoreInt32(&dispatcher.draining, 1)
+}
+
+func (dispatcher *Dispatcher) Draining() bool {
+	return atomic.LoadInt32(&dispatcher.draining) == 1
+}
+
+// Readiness probe handler, to register on the dispatcher's routes (ex: `routes.GET("/ready", dispatcher.Readiness)`)
+func (dispatcher *Dispatcher) Readiness(h *Http) HttpResponse {
+	if dispatcher.Draining() {
+		return JsonErrorResponse(http.StatusServiceUnavailable, h.Request, "Draining")
+	}
+
+	return TextResponse(http.StatusOK, "Ready")
+}
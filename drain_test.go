@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+func TestDispatcherInFlight_when_concurrentRequests(t *testing.T) {
+	// GIVEN
+	const concurrentRequests = 5
+	var entered sync.WaitGroup
+	entered.Add(concurrentRequests)
+	release := make(chan struct{})
+	handler := func(h *Http) HttpResponse {
+		entered.Done()
+		<-release
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/slow", handler), nil)
+
+	// WHEN
+	var done sync.WaitGroup
+	done.Add(concurrentRequests)
+	for i := 0; i < concurrentRequests; i++ {
+		go func() {
+			defer done.Done()
+			dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+	}
+	entered.Wait()
+	actualInFlight := dispatcher.InFlight()
+	close(release)
+	done.Wait()
+
+	// THEN
+	if actualInFlight != concurrentRequests {
+		t.Errorf("Actual: '%v', expected: '%v'", actualInFlight, concurrentRequests)
+	}
+
+	if dispatcher.InFlight() != 0 {
+		t.Errorf("Actual: '%v', expected: '%v'", dispatcher.InFlight(), 0)
+	}
+}
+
+func TestDispatcherReadiness_when_nominal(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	dispatcher := NewDispatcher(routes, nil)
+	routes.GET("/ready", dispatcher.Readiness)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestDispatcherReadiness_when_draining(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	dispatcher := NewDispatcher(routes, nil)
+	routes.GET("/ready", dispatcher.Readiness)
+	dispatcher.Drain()
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	// THEN
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusServiceUnavailable)
+	}
+}
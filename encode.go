@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"reflect"
+)
+
+// HTTP RESPONSE (ENCODED)
+// Encodes the body straight into the connection instead of marshaling it into a `[]byte` first (JSON slices and arrays
+// element by element, XML token by token): no Content-Length
+// is sent (chunked), `Dispatcher.ResponseTransform` isn't applied, and an encoding error can't change the status anymore
+type EncodedResponseWriter struct {
+	contentType string
+	statusCode int
+	responseBody interface{}
+	encode func(writer *bufio.Writer, responseBody interface{}) error
+}
+
+func (r *EncodedResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	// A Content-Type set beforehand takes precedence, like for `ResponseWriter`
+	if response.Header().Get("Content-Type") == "" {
+		response.Header().Set("Content-Type", responseContentType(request, r.contentType))
+	}
+
+	response.WriteHeader(r.statusCode)
+
+	writer := bufio.NewWriter(response)
+	if err := r.encode(writer, r.responseBody); err != nil {
+		log.Debug("[EncodedResponseWriter#write] encode => %s", err.Error())
+		return
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Debug("[EncodedResponseWriter#write] Flush => %s", err.Error())
+	}
+}
+
+// `json.Encoder` marshals the whole value in memory before writing it, so slices and arrays are encoded element by
+// element instead: only one element is held in memory at a time, the buffered writer sends the rest
+func encodeJson(writer *bufio.Writer, responseBody interface{}) error {
+	value := reflect.ValueOf(responseBody)
+	for value.Kind() == reflect.Ptr && !value.IsNil() && !implementsMarshaler(value) {
+		value = value.Elem()
+	}
+
+	isList := (value.Kind() == reflect.Slice && !value.IsNil()) || value.Kind() == reflect.Array
+	if !isList || value.Type().Elem().Kind() == reflect.Uint8 || implementsMarshaler(value) {
+		// Ex: a struct, a map, `[]byte` (base64) or a type with its own encoding
+		return json.NewEncoder(writer).Encode(responseBody)
+	}
+
+	if err := writer.WriteByte('['); err != nil {
+		return err
+	}
+	for i := 0; i < value.Len(); i++ {
+		if i > 0 {
+			if err := writer.WriteByte(','); err != nil {
+				return err
+			}
+		}
+
+		element, err := json.Marshal(value.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(element); err != nil {
+			return err
+		}
+	}
+
+	// Same trailing newline as `json.Encoder`
+	_, err := writer.WriteString("]\n")
+	return err
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func implementsMarshaler(value reflect.Value) bool {
+	return value.Type().Implements(jsonMarshalerType) || value.Type().Implements(textMarshalerType)
+}
+
+func encodeXml(writer *bufio.Writer, responseBody interface{}) error {
+	return xml.NewEncoder(writer).Encode(responseBody)
+}
+
+// Like `JsonResponse()` but for large bodies, see `EncodedResponseWriter`
+func JsonEncodedResponse(statusCode int, responseBody interface{}) HttpResponse {
+	return &EncodedResponseWriter{
+		contentType: "application/json",
+		statusCode: statusCode,
+		responseBody: responseBody,
+		encode: encodeJson}
+}
+
+// Like `XmlResponse()` but for large bodies, see `EncodedResponseWriter`
+func XmlEncodedResponse(statusCode int, responseBody interface{}) HttpResponse {
+	return &EncodedResponseWriter{
+		contentType: "application/xml",
+		statusCode: statusCode,
+		responseBody: responseBody,
+		encode: encodeXml}
+}
@@ -0,0 +1,171 @@
+package rest
+
+import (
+	"testing"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+)
+
+type mockLargeItem struct {
+	ID int
+	Name string
+	Tags []string
+}
+
+func newMockLargePayload() []mockLargeItem {
+	items := make([]mockLargeItem, 10000)
+	for i := range items {
+		items[i] = mockLargeItem{ID: i, Name: strings.Repeat("n", 32), Tags: []string{"a", "b", "c"}}
+	}
+	return items
+}
+
+// Discards the body, so that benchmarks only measure the encoding
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(statusCode int) {}
+
+func TestJsonEncodedResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+	body := map[string]int{"a": 1}
+
+	// WHEN
+	JsonEncodedResponse(http.StatusOK, body).write(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// THEN
+	var actual map[string]int
+	if err := json.Unmarshal(recorder.Body.Bytes(), &actual); err != nil || actual["a"] != 1 {
+		t.Errorf("Actual: '%v' (%v), expected: '%v'", recorder.Body.String(), err, body)
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/json" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "application/json")
+	}
+
+	if actual := recorder.Header().Get("Content-Length"); actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+}
+
+func TestXmlEncodedResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	XmlEncodedResponse(http.StatusOK, &ErrorResponse{Message: "a"}).write(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// THEN
+	if expected := "<ErrorResponse><Date></Date><Message>a</Message><Method></Method><Path></Path></ErrorResponse>"; recorder.Body.String() != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), expected)
+	}
+}
+
+// Live heap while the body is written: measured at each write, once the garbage is collected
+type peakHeapResponseWriter struct {
+	discardResponseWriter
+	baseline uint64
+	peak uint64
+}
+
+func newPeakHeapResponseWriter() *peakHeapResponseWriter {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	return &peakHeapResponseWriter{discardResponseWriter: discardResponseWriter{header: make(http.Header)}, baseline: stats.HeapAlloc}
+}
+
+func (w *peakHeapResponseWriter) Write(data []byte) (int, error) {
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	if stats.HeapAlloc > w.baseline && stats.HeapAlloc - w.baseline > w.peak {
+		w.peak = stats.HeapAlloc - w.baseline
+	}
+	return len(data), nil
+}
+
+// Reports the peak of live heap while writing (`peak-heap-B/op`), rather than the allocations: the point of encoding
+// straight into the connection is not to hold the whole body in memory
+func benchmarkPeakHeap(b *testing.B, response func() HttpResponse) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	var peak uint64
+
+	for i := 0; i < b.N; i++ {
+		writer := newPeakHeapResponseWriter()
+		response().write(writer, request)
+		if writer.peak > peak {
+			peak = writer.peak
+		}
+	}
+
+	b.ReportMetric(float64(peak), "peak-heap-B/op")
+}
+
+func BenchmarkJsonResponse_large(b *testing.B) {
+	payload := newMockLargePayload()
+	benchmarkPeakHeap(b, func() HttpResponse {
+		return JsonResponse(http.StatusOK, payload)
+	})
+}
+
+func BenchmarkJsonEncodedResponse_large(b *testing.B) {
+	payload := newMockLargePayload()
+	benchmarkPeakHeap(b, func() HttpResponse {
+		return JsonEncodedResponse(http.StatusOK, payload)
+	})
+}
+
+func TestJsonEncodedResponse_when_slice(t *testing.T) {
+	cases := map[string]interface{}{
+		"slice": []mockLargeItem{{ID: 1, Name: "a"}, {ID: 2, Name: "<b>"}},
+		"pointer to array": &[2]int{1, 2},
+		"empty slice": []string{},
+		"nil slice": []string(nil),
+		"bytes": []byte("abc"),
+	}
+
+	for name, body := range cases {
+		// GIVEN
+		recorder := httptest.NewRecorder()
+		var expected bytes.Buffer
+		json.NewEncoder(&expected).Encode(body)
+
+		// WHEN
+		JsonEncodedResponse(http.StatusOK, body).write(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		// THEN
+		if recorder.Body.String() != expected.String() {
+			t.Errorf("Case '%s' => actual: '%s', expected: '%s'", name, recorder.Body.String(), expected.String())
+		}
+	}
+}
+
+func TestJsonEncodedResponse_when_peakHeap(t *testing.T) {
+	// GIVEN
+	payload := newMockLargePayload()
+	encoded, _ := json.Marshal(payload)
+	writer := newPeakHeapResponseWriter()
+
+	// WHEN
+	JsonEncodedResponse(http.StatusOK, payload).write(writer, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// THEN
+	if writer.peak >= uint64(len(encoded)) / 4 {
+		t.Errorf("Actual: '%d' bytes, expected less than '%d' bytes", writer.peak, len(encoded) / 4)
+	}
+}
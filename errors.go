@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error carrying an HTTP status, returned by handlers with the `(rest.HttpResponse, error)` signature
+type HTTPError struct {
+	Code int
+	Message string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+func NewHTTPError(code int, message string) *HTTPError {
+	return &HTTPError{Code: code, Message: message}
+}
+
+func BadRequest(message string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, message)
+}
+
+func Unauthorized(message string) *HTTPError {
+	return NewHTTPError(http.StatusUnauthorized, message)
+}
+
+func Forbidden(message string) *HTTPError {
+	return NewHTTPError(http.StatusForbidden, message)
+}
+
+func NotFound(message string) *HTTPError {
+	return NewHTTPError(http.StatusNotFound, message)
+}
+
+func Conflict(message string) *HTTPError {
+	return NewHTTPError(http.StatusConflict, message)
+}
+
+// A path variable doesn't have the expected type, returned by `Http.PathInt()` and raised by `Http.MustPathInt()`.
+// Answered with a `400 Bad Request`.
+type PathVariableError struct {
+	Name string
+	// Ex: `int`
+	Type string
+	Value string
+}
+
+func (e *PathVariableError) Error() string {
+	return fmt.Sprintf("Path variable '%s' must be of type '%s' but was '%s'", e.Name, e.Type, e.Value)
+}
+
+// Maps an error returned by a handler to a `JsonErrorResponse`: an `HTTPError` gives its status and message, a
+// `PathVariableError` gives a `400`, a body
+// exceeding `Dispatcher.MaxBodySize` gives a `413`, any other error is a `500 Internal Server Error` whose message
+// isn't exposed to the client
+func errorResponse(request *http.Request, err error) HttpResponse {
+	var httpError *HTTPError
+	if errors.As(err, &httpError) {
+		return JsonErrorResponse(httpError.Code, request, httpError.Message)
+	}
+
+	var pathVariableError *PathVariableError
+	if errors.As(err, &pathVariableError) {
+		return JsonErrorResponse(http.StatusBadRequest, request, pathVariableError.Error())
+	}
+
+	// Ex: `Http.RawBody()` exceeding `Dispatcher.MaxBodySize`
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		return JsonErrorResponse(http.StatusRequestEntityTooLarge, request, http.StatusText(http.StatusRequestEntityTooLarge))
+	}
+
+	log.Debug("[errorResponse] Method: '%s' | Path: '%s' | Error => %s", request.Method, request.URL.Path, err.Error())
+	return JsonErrorResponse(http.StatusInternalServerError, request, http.StatusText(http.StatusInternalServerError))
+}
+
+// Error response of the dispatcher itself (ex: `404 Not Found`, `405 Method Not Allowed`): XML when the request's
+// `Accept` header prefers it, JSON otherwise
+func negotiatedErrorResponse(statusCode int, request *http.Request) HttpResponse {
+	if negotiateContentType(request.Header.Get("Accept"), []string{"application/json", "application/xml"}) == "application/xml" {
+		return XmlErrorResponse(statusCode, request, http.StatusText(statusCode))
+	}
+
+	return JsonErrorResponse(statusCode, request, http.StatusText(statusCode))
+}
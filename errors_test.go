@@ -0,0 +1,240 @@
+package rest
+
+import (
+	"testing"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func serveMockErrorHandler(t *testing.T, err error) (*httptest.ResponseRecorder, ErrorResponse) {
+	handler := func(h *Http) (HttpResponse, error) {
+		return nil, err
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	var body ErrorResponse
+	if unmarshalErr := json.Unmarshal(recorder.Body.Bytes(), &body); unmarshalErr != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", unmarshalErr.Error(), recorder.Body.String())
+	}
+
+	return recorder, body
+}
+
+func TestHTTPError_when_constructors(t *testing.T) {
+	// GIVEN
+	expectations := map[int]*HTTPError{
+		http.StatusBadRequest: BadRequest("bad"),
+		http.StatusUnauthorized: Unauthorized("unauthorized"),
+		http.StatusForbidden: Forbidden("forbidden"),
+		http.StatusNotFound: NotFound("not found"),
+		http.StatusConflict: Conflict("conflict"),
+	}
+
+	for expected, httpError := range expectations {
+		// WHEN
+		recorder, body := serveMockErrorHandler(t, httpError)
+
+		// THEN
+		if recorder.Code != expected {
+			t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, expected)
+		}
+
+		if body.Message != httpError.Message {
+			t.Errorf("Actual: '%s', expected: '%s'", body.Message, httpError.Message)
+		}
+	}
+}
+
+func TestHTTPError_when_wrapped(t *testing.T) {
+	// GIVEN
+	err := fmt.Errorf("loading user: %w", NotFound("no user 42"))
+
+	// WHEN
+	recorder, body := serveMockErrorHandler(t, err)
+
+	// THEN
+	if recorder.Code != http.StatusNotFound || body.Message != "no user 42" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, body.Message, http.StatusNotFound, "no user 42")
+	}
+}
+
+func TestHTTPError_when_otherError(t *testing.T) {
+	// GIVEN
+	err := errors.New("database password leaked")
+
+	// WHEN
+	recorder, body := serveMockErrorHandler(t, err)
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusInternalServerError)
+	}
+
+	if body.Message == err.Error() {
+		t.Errorf("Actual: '%s', expected the error message to be hidden", body.Message)
+	}
+}
+
+func TestHTTPError_when_nilError(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) (HttpResponse, error) {
+		return TextResponse(200, "ok"), nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ok" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "ok")
+	}
+}
+
+func TestDispatcher_when_notFoundJson(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes(), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	// THEN
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", err.Error(), recorder.Body.String())
+	}
+
+	if recorder.Code != http.StatusNotFound || body.Message != "Not Found" || body.Path != "/users" {
+		t.Errorf("Actual: '%d %+v', expected: '%d %s'", recorder.Code, body, http.StatusNotFound, "Not Found")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/json" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/json")
+	}
+}
+
+func TestDispatcher_when_notFoundXml(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes(), nil)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Accept", "application/json;q=0.5, application/xml")
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	var body ErrorResponse
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", err.Error(), recorder.Body.String())
+	}
+
+	if recorder.Code != http.StatusNotFound || body.Message != "Not Found" {
+		t.Errorf("Actual: '%d %+v', expected: '%d %s'", recorder.Code, body, http.StatusNotFound, "Not Found")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/xml" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/xml")
+	}
+}
+
+func TestDispatcher_when_methodNotAllowedBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/users", nil))
+
+	// THEN
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", err.Error(), recorder.Body.String())
+	}
+
+	if recorder.Code != http.StatusMethodNotAllowed || body.Message != "Method Not Allowed" {
+		t.Errorf("Actual: '%d %+v', expected: '%d %s'", recorder.Code, body, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+
+	if actual := recorder.Header().Get("Allow"); actual != "GET, HEAD" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "GET, HEAD")
+	}
+}
+
+func TestHttpPathInt_when_nominal(t *testing.T) {
+	// GIVEN
+	h := &Http{PathVariables: map[string]string{"id": "42"}}
+
+	// WHEN
+	actual, err := h.PathInt("id")
+
+	// THEN
+	if actual != 42 || err != nil {
+		t.Errorf("Actual: '%v %v', expected: '%v %v'", actual, err, 42, nil)
+	}
+}
+
+func TestHttpPathInt_when_error_notNumeric(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) (HttpResponse, error) {
+		id, err := h.PathInt("id")
+		if err != nil {
+			return nil, err
+		}
+		return JsonResponse(http.StatusOK, id), nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+
+	// THEN
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", err.Error(), recorder.Body.String())
+	}
+
+	expected := "Path variable 'id' must be of type 'int' but was 'abc'"
+	if recorder.Code != http.StatusBadRequest || body.Message != expected {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, body.Message, http.StatusBadRequest, expected)
+	}
+}
+
+func TestHttpMustPathInt_when_error_notNumeric(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, h.MustPathInt("id"))
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	dispatcher.PanicHandler = func(h *Http, recovered interface{}) HttpResponse {
+		t.Errorf("Actual: '%v', expected: '%v'", "PanicHandler called", "a 400")
+		return nil
+	}
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+
+	// THEN
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", err.Error(), recorder.Body.String())
+	}
+
+	expected := "Path variable 'id' must be of type 'int' but was 'abc'"
+	if recorder.Code != http.StatusBadRequest || body.Message != expected {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, body.Message, http.StatusBadRequest, expected)
+	}
+}
@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pre-filter rejecting URLs longer than `maxLength` with `414 URI Too Long`
+func MaxURLLengthFilter(maxLength int) FilterFunc {
+	return func(response http.ResponseWriter, request *http.Request) bool {
+		if length := len(request.URL.String()); length > maxLength {
+			JsonErrorResponse(http.StatusRequestURITooLong, request, fmt.Sprintf("URL length %d exceeds the limit of %d", length, maxLength)).write(response, request)
+			return false
+		}
+
+		return true
+	}
+}
+
+// Pre-filter requiring the header: `400 Bad Request` if it's absent, `401 Unauthorized` if `validate` rejects its value
+// (ex: an unknown `X-API-Key`). A nil `validate` only checks the presence.
+func RequireHeaderFilter(name string, validate func(string) bool) FilterFunc {
+	return func(response http.ResponseWriter, request *http.Request) bool {
+		value := request.Header.Get(name)
+		if value == "" {
+			JsonErrorResponse(http.StatusBadRequest, request, fmt.Sprintf("Header '%s' is required", name)).write(response, request)
+			return false
+		}
+
+		if validate != nil && !validate(value) {
+			JsonErrorResponse(http.StatusUnauthorized, request, fmt.Sprintf("Header '%s' is invalid", name)).write(response, request)
+			return false
+		}
+
+		return true
+	}
+}
+
+// Cross-Origin Resource Sharing settings of `CORSFilter()`
+type CORSConfig struct {
+	// Origins allowed to call the API (ex: `https://example.com`), `*` allows any origin
+	AllowedOrigins []string
+	// Methods allowed by the preflights, the requested method if empty
+	AllowedMethods []string
+	// Request headers allowed by the preflights, the requested headers if empty
+	AllowedHeaders []string
+	// Whether the browser may send the cookies and the credentials
+	AllowCredentials bool
+	// How long the browser may cache a preflight's result, zero doesn't send `Access-Control-Max-Age`
+	MaxAge time.Duration
+}
+
+// Pre-filter answering the CORS headers to the allowed origins. A preflight (`OPTIONS` with `Access-Control-Request-Method`)
+// is answered with `204 No Content` and stops the treatment, even if no `OPTIONS` route is registered.
+// Requests from other origins get no CORS header, so that the browser blocks them.
+func CORSFilter(config CORSConfig) FilterFunc {
+	allowsAnyOrigin := false
+	allowedOrigins := make(map[string]bool, len(config.AllowedOrigins))
+	for _, origin := range config.AllowedOrigins {
+		allowsAnyOrigin = allowsAnyOrigin || origin == "*"
+		allowedOrigins[strings.ToLower(origin)] = true
+	}
+
+	return func(response http.ResponseWriter, request *http.Request) bool {
+		origin := request.Header.Get("Origin")
+		response.Header().Add("Vary", "Origin")
+		if origin == "" || !(allowsAnyOrigin || allowedOrigins[strings.ToLower(origin)]) {
+			return true
+		}
+
+		// Browsers refuse `*` along with credentials
+		if allowsAnyOrigin && !config.AllowCredentials {
+			response.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			response.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if config.AllowCredentials {
+			response.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		requestedMethod := request.Header.Get("Access-Control-Request-Method")
+		if request.Method != http.MethodOptions || requestedMethod == "" {
+			return true
+		}
+
+		// Preflight
+		allowedMethods := strings.Join(config.AllowedMethods, ", ")
+		if allowedMethods == "" {
+			allowedMethods = requestedMethod
+		}
+		response.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+
+		allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+		if allowedHeaders == "" {
+			allowedHeaders = request.Header.Get("Access-Control-Request-Headers")
+		}
+		if allowedHeaders != "" {
+			response.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+		}
+
+		if config.MaxAge > 0 {
+			response.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+		}
+
+		response.WriteHeader(http.StatusNoContent)
+		return false
+	}
+}
@@ -0,0 +1,245 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func TestMaxURLLengthFilter_when_error_urlTooLong(t *testing.T) {
+	// GIVEN
+	filter := MaxURLLengthFilter(16)
+	request := httptest.NewRequest(http.MethodGet, "/a?q=" + strings.Repeat("a", 16), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	actual := filter(recorder, request)
+
+	// THEN
+	if actual == true {
+		t.Errorf("Actual: '%t', expected: '%t'", actual, false)
+	}
+
+	if recorder.Code != http.StatusRequestURITooLong {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusRequestURITooLong)
+	}
+}
+
+func TestMaxURLLengthFilter_when_urlUnderLimit(t *testing.T) {
+	// GIVEN
+	filter := MaxURLLengthFilter(16)
+	request := httptest.NewRequest(http.MethodGet, "/a?q=a", nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	actual := filter(recorder, request)
+
+	// THEN
+	if actual == false {
+		t.Errorf("Actual: '%t', expected: '%t'", actual, true)
+	}
+}
+
+func TestWithFilters_when_filterStopsTreatment(t *testing.T) {
+	// GIVEN
+	called := false
+	handler := func(h *Http) HttpResponse {
+		called = true
+		return NoContentResponse()
+	}
+	authFilter := func(response http.ResponseWriter, request *http.Request) bool {
+		response.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/admin", handler, WithFilters(authFilter)).GET("/public", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	// THEN
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusUnauthorized)
+	}
+
+	if called {
+		t.Errorf("Actual: '%v', expected: '%v'", called, false)
+	}
+}
+
+func TestWithFilters_when_otherRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	authFilter := func(response http.ResponseWriter, request *http.Request) bool {
+		response.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/admin", handler, WithFilters(authFilter)).GET("/public", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/public", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestWithFilters_when_order(t *testing.T) {
+	// GIVEN
+	var order []string
+	handler := func(h *Http) HttpResponse {
+		order = append(order, "handler")
+		return NoContentResponse()
+	}
+	recordingFilter := func(name string) FilterFunc {
+		return func(response http.ResponseWriter, request *http.Request) bool {
+			order = append(order, name)
+			return true
+		}
+	}
+	filters := NewFilters().AddPreFilter(recordingFilter("pre")).AddPostFilter(recordingFilter("post"))
+	routes := NewRoutes().GET("/a", handler, WithFilters(recordingFilter("route1"), recordingFilter("route2")))
+
+	// WHEN
+	NewDispatcher(routes, filters).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	expected := []string{"pre", "route1", "route2", "handler", "post"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Actual: '%v', expected: '%v'", order, expected)
+	}
+}
+
+func TestRequireHeaderFilter_when_nominal(t *testing.T) {
+	validate := func(value string) bool {
+		return value == "secret"
+	}
+	cases := map[string]struct {
+		value string
+		expectedResult bool
+		expectedStatus int
+	}{
+		"present valid": {"secret", true, http.StatusOK},
+		"present invalid": {"guess", false, http.StatusUnauthorized},
+		"absent": {"", false, http.StatusBadRequest},
+	}
+
+	for name, c := range cases {
+		// GIVEN
+		request := httptest.NewRequest(http.MethodGet, "/a", nil)
+		if c.value != "" {
+			request.Header.Set("X-API-Key", c.value)
+		}
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		actual := RequireHeaderFilter("X-API-Key", validate)(recorder, request)
+
+		// THEN
+		if actual != c.expectedResult || recorder.Code != c.expectedStatus {
+			t.Errorf("Actual: '%v %d' for '%s', expected: '%v %d'", actual, recorder.Code, name, c.expectedResult, c.expectedStatus)
+		}
+	}
+}
+
+func TestRequireHeaderFilter_when_presenceOnly(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("X-Tenant-ID", "acme")
+
+	// WHEN
+	actual := RequireHeaderFilter("X-Tenant-ID", nil)(httptest.NewRecorder(), request)
+
+	// THEN
+	if !actual {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, true)
+	}
+}
+
+func TestCORSFilter_when_preflight(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, "ok")
+	}
+	filters := NewFilters().AddPreFilter(CORSFilter(CORSConfig{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge: 10 * time.Minute}))
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler).POST("/users", handler), filters)
+	request := httptest.NewRequest(http.MethodOptions, "/users", nil)
+	request.Header.Set("Origin", "https://example.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+
+	for name, expected := range map[string]string{
+		"Access-Control-Allow-Origin": "https://example.com",
+		"Access-Control-Allow-Methods": "GET, POST",
+		"Access-Control-Allow-Headers": "Content-Type, Authorization",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age": "600",
+		"Vary": "Origin"} {
+		if actual := recorder.Header().Get(name); actual != expected {
+			t.Errorf("%s => actual: '%v', expected: '%v'", name, actual, expected)
+		}
+	}
+}
+
+func TestCORSFilter_when_simpleRequest(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, "ok")
+	}
+	filters := NewFilters().AddPreFilter(CORSFilter(CORSConfig{AllowedOrigins: []string{"*"}}))
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), filters)
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Origin", "https://example.com")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != `"ok"` {
+		t.Errorf("Actual: '%v %v', expected: '%v %v'", recorder.Code, recorder.Body.String(), http.StatusOK, `"ok"`)
+	}
+
+	if actual := recorder.Header().Get("Access-Control-Allow-Origin"); actual != "*" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "*")
+	}
+}
+
+func TestCORSFilter_when_originNotAllowed(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, "ok")
+	}
+	filters := NewFilters().AddPreFilter(CORSFilter(CORSConfig{AllowedOrigins: []string{"https://example.com"}}))
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), filters)
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Origin", "https://evil.com")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if actual := recorder.Header().Get("Access-Control-Allow-Origin"); actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+}
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Unmarshaler of `application/x-www-form-urlencoded` bodies (ex: HTML form posts) into a pointer to a struct.
+// A field is looked up by its `form` tag, otherwise by its name (case-insensitive). Supported kinds: see `Http.Bind()`.
+func unmarshalForm(data []byte, v interface{}) error {
+	dstValue := reflect.ValueOf(v)
+	if dstValue.Kind() != reflect.Ptr || dstValue.IsNil() || dstValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("[unmarshalForm] v must be a non-nil pointer to a struct but was '%T'", v)
+	}
+
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return fmt.Errorf("[unmarshalForm] url.ParseQuery => %w", err)
+	}
+
+	structValue := dstValue.Elem()
+	for _, field := range schemaOf(structValue.Type()).fields {
+		value, found := lookupFormValue(form, field)
+		if !found {
+			continue
+		}
+
+		if err := setFieldValue(structValue.Field(field.index), value); err != nil {
+			return fmt.Errorf("'%s' %s", field.name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// First value of the field's form key
+func lookupFormValue(form url.Values, field fieldSchema) (string, bool) {
+	if field.formKey != "" {
+		return form.Get(field.formKey), form.Has(field.formKey)
+	}
+
+	for name, values := range form {
+		if strings.EqualFold(name, field.name) && len(values) > 0 {
+			return values[0], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type mockFormBody struct {
+	Name string `form:"user_name"`
+	Age int
+	ignored string
+}
+
+func TestUnmarshalForm_when_nominal(t *testing.T) {
+	// GIVEN
+	body := new(mockFormBody)
+
+	// WHEN
+	err := unmarshalForm([]byte("user_name=Jane+Doe&age=42&ignored=x"), body)
+
+	// THEN
+	if err != nil {
+		t.Errorf("Actual: '%v', expected: '%v'", err, nil)
+	}
+
+	expected := mockFormBody{Name: "Jane Doe", Age: 42}
+	if *body != expected {
+		t.Errorf("Actual: '%+v', expected: '%+v'", *body, expected)
+	}
+}
+
+func TestUnmarshalForm_when_error_invalidInt(t *testing.T) {
+	// GIVEN
+	body := new(mockFormBody)
+
+	// WHEN
+	err := unmarshalForm([]byte("Age=old"), body)
+
+	// THEN
+	if err == nil || !strings.Contains(err.Error(), "'Age' must be an integer") {
+		t.Errorf("Actual: '%v', expected: '%v'", err, "'Age' must be an integer but was 'old'")
+	}
+}
+
+func TestUnmarshalForm_when_error_notStruct(t *testing.T) {
+	// GIVEN
+	var body map[string]string
+
+	// WHEN
+	err := unmarshalForm([]byte("a=b"), &body)
+
+	// THEN
+	if err == nil {
+		t.Errorf("Actual: '%v', expected: '%v'", err, "an error")
+	}
+}
+
+func TestDispatcher_when_formBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, requestBody *mockFormBody) HttpResponse {
+		return JsonResponse(http.StatusOK, requestBody)
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/users", handler), nil)
+	request := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("user_name=Jane&Age=42"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	expected := `{"Name":"Jane","Age":42}`
+	if recorder.Code != http.StatusOK || recorder.Body.String() != expected {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, expected)
+	}
+}
@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Compresses the body when its Content-Type is textual, the decision is made when the header is written
+type gzipResponseWriter struct {
+	http.ResponseWriter
+
+	// Decided when the header is written
+	compress bool
+	// Opened by the first non-empty write, so that a response without body doesn't get an empty gzip stream
+	gzipWriter *gzip.Writer
+	headerWritten bool
+}
+
+// JSON, XML and text bodies, including suffixed types (ex: `application/vnd.api+json`)
+func isCompressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") || strings.HasSuffix(mediaType, "xml")
+}
+
+func (w *gzipResponseWriter) WriteHeader(statusCode int) {
+	if w.headerWritten {
+		return
+	}
+	w.headerWritten = true
+
+	header := w.Header()
+	hasBody := statusCode != http.StatusNoContent && statusCode != http.StatusNotModified
+	// An encoded body (ex: relayed by a reverse proxy, or a precompressed file) is passed through untouched
+	if hasBody && header.Get("Content-Encoding") == "" && isCompressible(header.Get("Content-Type")) {
+		// The Content-Length of the uncompressed body is stale
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		w.compress = true
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.compress {
+		return w.ResponseWriter.Write(data)
+	}
+
+	if len(data) == 0 {
+		return 0, nil
+	}
+	if w.gzipWriter == nil {
+		w.gzipWriter = gzip.NewWriter(w.ResponseWriter)
+	}
+	return w.gzipWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gzipWriter != nil {
+		w.gzipWriter.Flush()
+	}
+	flush(w.ResponseWriter)
+}
+
+// Gives `http.ResponseController` access to the connection (ex: write deadlines)
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Writes the gzip footer if a body was written, must be called once the response is complete
+func (w *gzipResponseWriter) close() {
+	if w.gzipWriter != nil {
+		if err := w.gzipWriter.Close(); err != nil {
+			log.Debug("[gzipResponseWriter#close] => %s", err.Error())
+		}
+	}
+}
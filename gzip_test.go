@@ -0,0 +1,234 @@
+package rest
+
+import (
+	"testing"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func TestDispatcher_when_gzip(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, map[string]string{"users": strings.Repeat("Alice,", 100)})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+	dispatcher.Gzip = true
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Accept-Encoding", "gzip, deflate")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Encoding"); actual != "gzip" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "gzip")
+	}
+
+	if actual := recorder.Header().Get("Content-Length"); actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+
+	reader, err := gzip.NewReader(recorder.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	actual, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	expected, _ := json.Marshal(map[string]string{"users": strings.Repeat("Alice,", 100)})
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, expected)
+	}
+}
+
+func TestDispatcher_when_gzipNotAccepted(t *testing.T) {
+	for _, acceptEncoding := range []string{"", "deflate", "gzip;q=0, *"} {
+		// GIVEN
+		handler := func(h *Http) HttpResponse {
+			return JsonResponse(http.StatusOK, map[string]string{"users": strings.Repeat("Alice,", 100)})
+		}
+		dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+		dispatcher.Gzip = true
+		request := httptest.NewRequest(http.MethodGet, "/users", nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, request)
+
+		// THEN
+		if actual := recorder.Header().Get("Content-Encoding"); actual != "" {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", actual, acceptEncoding, "")
+		}
+
+		expected, _ := json.Marshal(map[string]string{"users": strings.Repeat("Alice,", 100)})
+		if !bytes.Equal(recorder.Body.Bytes(), expected) {
+			t.Errorf("Actual: '%s' for '%s', expected: '%s'", recorder.Body.Bytes(), acceptEncoding, expected)
+		}
+	}
+}
+
+func TestDispatcher_when_gzipNotCompressible(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return FileResponse(http.StatusOK, "image/png", "inline", 4, strings.NewReader("\x89PNG"))
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/image", handler), nil)
+	dispatcher.Gzip = true
+	request := httptest.NewRequest(http.MethodGet, "/image", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Encoding"); actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+
+	if recorder.Body.String() != "\x89PNG" || recorder.Header().Get("Content-Length") != "4" {
+		t.Errorf("Actual: '%q' (%s bytes), expected: '%q' (4 bytes)", recorder.Body.String(), recorder.Header().Get("Content-Length"), "\x89PNG")
+	}
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func TestDispatcher_when_gzipAndBodyAlreadyEncoded(t *testing.T) {
+	// GIVEN
+	compressed := gzipBytes(t, []byte(`{"a":1}`))
+	handler := func(h *Http) HttpResponse {
+		return FileResponse(http.StatusOK, "application/json", "inline", len(compressed), bytes.NewReader(compressed), map[string]string{"Content-Encoding": "gzip"})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.Gzip = true
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if !bytes.Equal(recorder.Body.Bytes(), compressed) {
+		t.Errorf("Actual: '%q', expected: '%q'", recorder.Body.Bytes(), compressed)
+	}
+
+	if actual := recorder.Header().Get("Content-Encoding"); actual != "gzip" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "gzip")
+	}
+}
+
+func TestDispatcher_when_gzipAndReverseProxy(t *testing.T) {
+	// GIVEN
+	compressed := gzipBytes(t, []byte(strings.Repeat("Alice,", 100)))
+	var forwardedAcceptEncoding string
+	upstream := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		forwardedAcceptEncoding = request.Header.Get("Accept-Encoding")
+		response.Header().Set("Content-Type", "text/plain")
+		response.Header().Set("Content-Encoding", "gzip")
+		response.Write(compressed)
+	}))
+	defer upstream.Close()
+	upstreamURL, _ := url.Parse(upstream.URL)
+	proxy := httputil.NewSingleHostReverseProxy(upstreamURL)
+
+	handler := func(h *Http) HttpResponse {
+		return HandlerResponse(proxy)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+	dispatcher.Gzip = true
+	request := httptest.NewRequest(http.MethodGet, "/users", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if forwardedAcceptEncoding != "gzip" {
+		t.Errorf("Actual: '%v', expected: '%v'", forwardedAcceptEncoding, "gzip")
+	}
+
+	if !bytes.Equal(recorder.Body.Bytes(), compressed) {
+		t.Errorf("Actual: '%q', expected: '%q'", recorder.Body.Bytes(), compressed)
+	}
+}
+
+func TestDispatcher_when_gzipAndEmptyBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return HandlerResponse(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			response.Header().Set("Content-Type", "text/plain")
+			response.WriteHeader(http.StatusAccepted)
+			response.Write(nil)
+		}))
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.Gzip = true
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusAccepted {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusAccepted)
+	}
+
+	// No gzip header and footer
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%q', expected: '%q'", recorder.Body.Bytes(), "")
+	}
+}
+
+func TestDispatcher_when_gzipAndHead(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, map[string]string{"users": strings.Repeat("Alice,", 100)})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+	dispatcher.Gzip = true
+	request := httptest.NewRequest(http.MethodHead, "/users", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%q', expected: '%q'", recorder.Body.Bytes(), "")
+	}
+
+	// The Content-Length of the GET response
+	expected, _ := json.Marshal(map[string]string{"users": strings.Repeat("Alice,", 100)})
+	if actual := recorder.Header().Get("Content-Length"); actual != strconv.Itoa(len(expected)) {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, len(expected))
+	}
+
+	if actual := recorder.Header().Get("Content-Encoding"); actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+}
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Routes bound to a host, returned by `Routes.Host()`
+type HostRoutes struct {
+	routes Routes
+	host string
+}
+
+// Binds the route to a host: it only matches requests whose `Host` header (port excluded) is the given host, case-insensitively.
+// A leading `*.` matches any subdomain (ex: `*.example.com` matches `api.example.com` but not `example.com`).
+func WithHost(host string) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.host = host
+	}
+}
+
+// Virtual host: the returned routes only match requests for the given host, see `WithHost()`.
+// For a given method and path, the routes bound to the request's host win over the routes without host.
+func (routes Routes) Host(host string) HostRoutes {
+	if host == "" {
+		panic("[Routes#Host] host must not be empty")
+	}
+
+	return HostRoutes{routes: routes, host: host}
+}
+
+// The caller's options are never modified
+func (hostRoutes HostRoutes) options(options []RouteOption) []RouteOption {
+	return append(options[:len(options):len(options)], WithHost(hostRoutes.host))
+}
+
+func (hostRoutes HostRoutes) GET(path string, handler interface{}, options ...RouteOption) HostRoutes {
+	hostRoutes.routes.addRoute(http.MethodGet, path, handler, hostRoutes.options(options))
+	return hostRoutes
+}
+
+func (hostRoutes HostRoutes) POST(path string, handler interface{}, options ...RouteOption) HostRoutes {
+	hostRoutes.routes.addRoute(http.MethodPost, path, handler, hostRoutes.options(options))
+	return hostRoutes
+}
+
+func (hostRoutes HostRoutes) PUT(path string, handler interface{}, options ...RouteOption) HostRoutes {
+	hostRoutes.routes.addRoute(http.MethodPut, path, handler, hostRoutes.options(options))
+	return hostRoutes
+}
+
+func (hostRoutes HostRoutes) PATCH(path string, handler interface{}, options ...RouteOption) HostRoutes {
+	hostRoutes.routes.addRoute(http.MethodPatch, path, handler, hostRoutes.options(options))
+	return hostRoutes
+}
+
+func (hostRoutes HostRoutes) DELETE(path string, handler interface{}, options ...RouteOption) HostRoutes {
+	hostRoutes.routes.addRoute(http.MethodDelete, path, handler, hostRoutes.options(options))
+	return hostRoutes
+}
+
+// Ex: `*.example.com` matches `api.example.com:8080`
+func matchHost(pattern string, requestHost string) bool {
+	if host, _, err := net.SplitHostPort(requestHost); err == nil {
+		requestHost = host
+	}
+
+	if strings.HasPrefix(pattern, "*.") {
+		suffix := pattern[1:]
+		return len(requestHost) > len(suffix) && strings.EqualFold(requestHost[len(requestHost)-len(suffix):], suffix)
+	}
+
+	return strings.EqualFold(pattern, requestHost)
+}
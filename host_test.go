@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestRoutesHost_when_samePathOnSeveralHosts(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	routes.Host("api.example.com").GET("/status", func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "api")
+	})
+	routes.Host("admin.example.com").GET("/status", func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "admin")
+	})
+	dispatcher := NewDispatcher(routes, nil)
+
+	for host, expected := range map[string]string{"api.example.com": "api", "ADMIN.example.com:8080": "admin"} {
+		request := httptest.NewRequest(http.MethodGet, "/status", nil)
+		request.Host = host
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, request)
+
+		// THEN
+		if actual := recorder.Body.String(); actual != expected {
+			t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+		}
+	}
+}
+
+func TestRoutesHost_when_hostBoundRouteWins(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes().GET("/users/{id}", func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "any")
+	})
+	routes.Host("*.example.com").GET("/users/{id}", func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "example "+h.PathVariables["id"])
+	})
+	dispatcher := NewDispatcher(routes, nil)
+
+	for host, expected := range map[string]string{"api.example.com": "example 42", "example.com": "any", "other.org": "any"} {
+		request := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		request.Host = host
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, request)
+
+		// THEN
+		if actual := recorder.Body.String(); actual != expected {
+			t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+		}
+	}
+}
+
+func TestRoutesHost_when_otherHost(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	routes.Host("api.example.com").GET("/status", func(h *Http) HttpResponse {
+		return NoContentResponse()
+	})
+	dispatcher := NewDispatcher(routes, nil)
+	request := httptest.NewRequest(http.MethodGet, "/status", nil)
+	request.Host = "admin.example.com"
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestRoutesHost_when_error_emptyHost(t *testing.T) {
+	// GIVEN
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Actual: '%v', expected: '%v'", "no panic", "panic")
+		}
+	}()
+
+	// WHEN
+	NewRoutes().Host("")
+}
+
+func TestMatchHost_when_nominal(t *testing.T) {
+	for _, testCase := range []struct {
+		pattern string
+		requestHost string
+		expected bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "Example.COM:443", true},
+		{"example.com", "api.example.com", false},
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "a.b.example.com:8080", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "badexample.com", false},
+		{"::1", "[::1]:8080", true},
+	} {
+		// WHEN
+		actual := matchHost(testCase.pattern, testCase.requestHost)
+
+		// THEN
+		if actual != testCase.expected {
+			t.Errorf("Actual: '%v', expected: '%v' (pattern: '%s', host: '%s')", actual, testCase.expected, testCase.pattern, testCase.requestHost)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strconv"
+)
+
+// HTTP RESPONSE (HTML)
+type HtmlResponseWriter struct {
+	statusCode int
+	template *template.Template
+	name string
+	data interface{}
+}
+
+func (r *HtmlResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	// Rendering first, so that a template error can still be answered with a 500
+	var buffer bytes.Buffer
+	if err := r.template.ExecuteTemplate(&buffer, r.name, r.data); err != nil {
+		log.Debug("[HtmlResponseWriter#write] ExecuteTemplate => %s", err.Error())
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", "text/html; charset=utf-8")
+	response.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+
+	response.WriteHeader(r.statusCode)
+
+	if _, err := response.Write(buffer.Bytes()); err != nil {
+		log.Debug("[HtmlResponseWriter#write] response.Write => %s", err.Error())
+	}
+}
+
+// Renders the template `name` of `tmpl` with `data`, for server-rendered pages
+func HtmlResponse(statusCode int, tmpl *template.Template, name string, data interface{}) HttpResponse {
+	if tmpl == nil {
+		panic("[HtmlResponse] tmpl must not be `nil`")
+	}
+
+	return &HtmlResponseWriter{
+		statusCode: statusCode,
+		template: tmpl,
+		name: name,
+		data: data}
+}
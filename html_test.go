@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"testing"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestHtmlResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	tmpl := template.Must(template.New("page").Parse(`<h1>Hello {{.Name}}</h1>`))
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	HtmlResponse(http.StatusOK, tmpl, "page", map[string]string{"Name": "<Alice>"}).write(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// THEN
+	if expected := "<h1>Hello &lt;Alice&gt;</h1>"; recorder.Body.String() != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), expected)
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/html; charset=utf-8" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "text/html; charset=utf-8")
+	}
+}
+
+func TestHtmlResponse_when_error_execution(t *testing.T) {
+	// GIVEN
+	tmpl := template.Must(template.New("page").Parse(`<h1>Hello {{.Name.Missing}}</h1>`))
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	HtmlResponse(http.StatusOK, tmpl, "page", map[string]string{"Name": "Alice"}).write(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusInternalServerError)
+	}
+
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), "")
+	}
+}
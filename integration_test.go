@@ -0,0 +1,249 @@
+package rest
+
+import (
+	"testing"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type mockUser struct {
+	ID string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Dispatcher served over a real HTTP connection, closed at the end of the test
+func newMockIntegrationServer(t *testing.T) *httptest.Server {
+	getUser := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, &mockUser{ID: h.PathVariables["id"], Name: "Alice"})
+	}
+	createUser := func(h *Http, user *mockUser) HttpResponse {
+		user.ID = "42"
+		return CreatedResponse("/users/42", user, nil)
+	}
+	getAdmin := func(h *Http) HttpResponse {
+		t.Errorf("The handler must not be called when a filter stops the treatment")
+		return NoContentResponse()
+	}
+	download := func(h *Http) HttpResponse {
+		content := "id,name\n42,Alice\n"
+		return FileResponse(http.StatusOK, "text/csv", "attachment; filename=\"users.csv\"", len(content), strings.NewReader(content))
+	}
+
+	routes := NewRoutes().
+		GET("/users/{id}", getUser).
+		POST("/users", createUser).
+		GET("/admin", getAdmin).
+		GET("/export", download)
+	filters := NewFilters().AddPreFilter(func(response http.ResponseWriter, request *http.Request) bool {
+		if request.URL.Path == "/admin" && request.Header.Get("Authorization") == "" {
+			response.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	})
+
+	server := httptest.NewServer(NewDispatcher(routes, filters))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func readMockBody(t *testing.T, response *http.Response) string {
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	return string(body)
+}
+
+func TestIntegration_when_getWithPathVariable(t *testing.T) {
+	// GIVEN
+	server := newMockIntegrationServer(t)
+
+	// WHEN
+	response, err := http.Get(server.URL + "/users/7")
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	body := readMockBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Actual: '%v', expected: '%v'", response.StatusCode, http.StatusOK)
+	}
+
+	if actual := response.Header.Get("Content-Type"); actual != "application/json" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "application/json")
+	}
+
+	if response.ContentLength != int64(len(body)) {
+		t.Errorf("Actual: '%v', expected: '%v'", response.ContentLength, len(body))
+	}
+
+	var user mockUser
+	if err := json.Unmarshal([]byte(body), &user); err != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", err.Error(), body)
+	}
+
+	if expected := (mockUser{ID: "7", Name: "Alice"}); user != expected {
+		t.Errorf("Actual: '%+v', expected: '%+v'", user, expected)
+	}
+}
+
+func TestIntegration_when_postWithJsonBody(t *testing.T) {
+	// GIVEN
+	server := newMockIntegrationServer(t)
+
+	// WHEN
+	response, err := http.Post(server.URL + "/users", "application/json", strings.NewReader(`{"name":"Bob"}`))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	body := readMockBody(t, response)
+
+	if response.StatusCode != http.StatusCreated {
+		t.Errorf("Actual: '%v', expected: '%v'", response.StatusCode, http.StatusCreated)
+	}
+
+	if actual := response.Header.Get("Location"); actual != "/users/42" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "/users/42")
+	}
+
+	if expected := `{"id":"42","name":"Bob"}`; body != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", body, expected)
+	}
+}
+
+func TestIntegration_when_error_notFound(t *testing.T) {
+	// GIVEN
+	server := newMockIntegrationServer(t)
+
+	// WHEN
+	response, err := http.Get(server.URL + "/unknown")
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	readMockBody(t, response)
+
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("Actual: '%v', expected: '%v'", response.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestIntegration_when_filterStopsTreatment(t *testing.T) {
+	// GIVEN
+	server := newMockIntegrationServer(t)
+
+	// WHEN
+	response, err := http.Get(server.URL + "/admin")
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	readMockBody(t, response)
+
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Actual: '%v', expected: '%v'", response.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestIntegration_when_fileDownload(t *testing.T) {
+	// GIVEN
+	server := newMockIntegrationServer(t)
+
+	// WHEN
+	response, err := http.Get(server.URL + "/export")
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	body := readMockBody(t, response)
+
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Actual: '%v', expected: '%v'", response.StatusCode, http.StatusOK)
+	}
+
+	expectedHeaders := map[string]string{
+		"Content-Type": "text/csv",
+		"Content-Disposition": "attachment; filename=\"users.csv\"",
+		"Content-Length": "17"}
+	for name, expected := range expectedHeaders {
+		if actual := response.Header.Get(name); actual != expected {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", actual, name, expected)
+		}
+	}
+
+	if expected := "id,name\n42,Alice\n"; body != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", body, expected)
+	}
+}
+
+func TestIntegration_when_hijack(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		hijacker, ok := h.Response.(http.Hijacker)
+		if !ok {
+			t.Errorf("Actual: '%T', expected: '%s'", h.Response, "an http.Hijacker")
+			return nil
+		}
+
+		conn, readWriter, err := hijacker.Hijack()
+		if err != nil {
+			t.Errorf("Unexpected error: '%s'", err.Error())
+			return nil
+		}
+		defer conn.Close()
+
+		readWriter.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		readWriter.Flush()
+		return nil
+	}
+	server := httptest.NewServer(NewDispatcher(NewRoutes().GET("/upgrade", handler), nil))
+	t.Cleanup(server.Close)
+
+	// WHEN
+	response, err := http.Get(server.URL + "/upgrade")
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	if actual := readMockBody(t, response); actual != "hijacked" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "hijacked")
+	}
+}
+
+func TestResponseRecorder_when_hijackNotSupported(t *testing.T) {
+	// GIVEN
+	recorder := &responseRecorder{ResponseWriter: httptest.NewRecorder()}
+
+	// WHEN
+	_, _, hijackErr := recorder.Hijack()
+	pushErr := recorder.Push("/app.js", nil)
+
+	// THEN
+	if !errors.Is(hijackErr, http.ErrNotSupported) {
+		t.Errorf("Actual: '%v', expected: '%v'", hijackErr, http.ErrNotSupported)
+	}
+
+	if !errors.Is(pushErr, http.ErrNotSupported) {
+		t.Errorf("Actual: '%v', expected: '%v'", pushErr, http.ErrNotSupported)
+	}
+
+	if recorder.written() {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.written(), false)
+	}
+}
@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Turns the maintenance mode on or off, safe to call while serving (ex: from a signal handler or an admin route).
+// When on, every request but `MaintenanceExemptPaths` is answered with `503 Service Unavailable`, and a `Retry-After`
+// header if `retryAfter` isn't zero.
+func (dispatcher *Dispatcher) SetMaintenanceMode(on bool, retryAfter time.Duration) {
+	atomic.StoreInt64(&dispatcher.maintenanceRetryAfter, int64(retryAfter))
+	if on {
+		atomic.StoreInt32(&dispatcher.maintenance, 1)
+	} else {
+		atomic.StoreInt32(&dispatcher.maintenance, 0)
+	}
+}
+
+func (dispatcher *Dispatcher) MaintenanceMode() bool {
+	return atomic.LoadInt32(&dispatcher.maintenance) == 1
+}
+
+func (dispatcher *Dispatcher) isMaintenanceExempt(calledPath string) bool {
+	for _, exemptPath := range dispatcher.MaintenanceExemptPaths {
+		if exemptPath == calledPath {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (dispatcher *Dispatcher) maintenanceResponse(request *http.Request) HttpResponse {
+	response := JsonErrorResponse(http.StatusServiceUnavailable, request, "Under maintenance")
+	if retryAfter := time.Duration(atomic.LoadInt64(&dispatcher.maintenanceRetryAfter)); retryAfter > 0 {
+		return WithHeaders(response, map[string]string{"Retry-After": retryAfterSeconds(retryAfter)})
+	}
+
+	return response
+}
@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func TestDispatcherSetMaintenanceMode_when_toggled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "ok")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+
+	for _, on := range []bool{true, false} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.SetMaintenanceMode(on, 90 * time.Second)
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		// THEN
+		if dispatcher.MaintenanceMode() != on {
+			t.Errorf("Actual: '%v', expected: '%v'", dispatcher.MaintenanceMode(), on)
+		}
+
+		expectedCode, expectedRetryAfter := http.StatusOK, ""
+		if on {
+			expectedCode, expectedRetryAfter = http.StatusServiceUnavailable, "90"
+		}
+
+		if recorder.Code != expectedCode {
+			t.Errorf("Maintenance '%v' => actual: '%d', expected: '%d'", on, recorder.Code, expectedCode)
+		}
+
+		if actual := recorder.Header().Get("Retry-After"); actual != expectedRetryAfter {
+			t.Errorf("Maintenance '%v' => actual: '%s', expected: '%s'", on, actual, expectedRetryAfter)
+		}
+	}
+}
+
+func TestDispatcherSetMaintenanceMode_when_unknownRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "ok")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+	dispatcher.SetMaintenanceMode(true, 0)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/unknown", nil))
+
+	// THEN
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusServiceUnavailable)
+	}
+
+	if actual := recorder.Header().Get("Retry-After"); actual != "" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "")
+	}
+}
+
+func TestDispatcherSetMaintenanceMode_when_exemptPath(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "ok")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/health", handler), nil)
+	dispatcher.MaintenanceExemptPaths = []string{"/health"}
+	dispatcher.SetMaintenanceMode(true, time.Minute)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusOK)
+	}
+}
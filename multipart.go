@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+)
+
+// Default of `Dispatcher.MultipartMemory`, same as Golang's `Request.FormValue()`
+const defaultMultipartMemory = 32 << 20
+
+// Parses the `multipart/form-data` body once, the url-encoded forms and the query are also parsed
+func (h *Http) parseMultipartForm() error {
+	if h.Request.MultipartForm != nil {
+		return nil
+	}
+
+	memory := h.multipartMemory
+	if memory <= 0 {
+		memory = defaultMultipartMemory
+	}
+
+	return h.Request.ParseMultipartForm(memory)
+}
+
+// Uploaded file of a `multipart/form-data` body, close it once read. A body exceeding `Dispatcher.MaxBodySize`
+// gives an error mapped to a `413` when returned by the handler.
+func (h *Http) FormFile(name string) (multipart.File, *multipart.FileHeader, error) {
+	if err := h.parseMultipartForm(); err != nil {
+		return nil, nil, err
+	}
+
+	return h.Request.FormFile(name)
+}
+
+// First value of a form field (`multipart/form-data` or url-encoded body, then query), or an empty string if absent
+func (h *Http) FormValue(name string) string {
+	// Url-encoded bodies aren't multipart but are parsed anyway
+	if err := h.parseMultipartForm(); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		log.Debug("[Http#FormValue] parseMultipartForm => %s", err.Error())
+	}
+
+	return h.Request.FormValue(name)
+}
@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"testing"
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func newMockMultipartRequest(t *testing.T) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField("title", "Report"); err != nil {
+		t.Fatal(err)
+	}
+	part, err := writer.CreateFormFile("file", "report.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("file content"))
+	writer.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/upload", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func mockUploadHandler(h *Http) (HttpResponse, error) {
+	file, header, err := h.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return TextResponse(http.StatusOK, h.FormValue("title") + " " + header.Filename + " " + string(content)), nil
+}
+
+func TestHttpFormFile_when_nominal(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().POST("/upload", mockUploadHandler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, newMockMultipartRequest(t))
+
+	// THEN
+	expected := "Report report.txt file content"
+	if recorder.Code != http.StatusOK || recorder.Body.String() != expected {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, expected)
+	}
+}
+
+func TestHttpFormFile_when_error_bodyTooLarge(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().POST("/upload", mockUploadHandler), nil)
+	dispatcher.MaxBodySize = 16
+	request := newMockMultipartRequest(t)
+	// Unknown length (ex: chunked), checked while reading
+	request.ContentLength = -1
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestHttpFormValue_when_urlEncoded(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodPost, "/a?b=2", strings.NewReader("a=1"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h := &Http{Response: httptest.NewRecorder(), Request: request}
+
+	// WHEN
+	actual := h.FormValue("a") + h.FormValue("b")
+
+	// THEN
+	if actual != "12" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "12")
+	}
+}
@@ -0,0 +1,111 @@
+package rest
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Media range of an `Accept` header. Ex: `application/*;q=0.8`
+type mediaRange struct {
+	mediaType string
+	quality float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	mediaRanges := make([]mediaRange, 0)
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, exists := params["q"]; exists {
+			if parsed, parseErr := strconv.ParseFloat(q, 64); parseErr == nil {
+				quality = parsed
+			}
+		}
+
+		mediaRanges = append(mediaRanges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	return mediaRanges
+}
+
+// Returns how specifically the media range matches the content type (0: no match, 1: `*/*`, 2: `type/*`, 3: exact)
+func (r mediaRange) match(contentType string) int {
+	switch {
+		case r.mediaType == contentType:
+			return 3
+		case strings.HasSuffix(r.mediaType, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(r.mediaType, "*")):
+			return 2
+		case r.mediaType == "*/*":
+			return 1
+	}
+
+	return 0
+}
+
+// Returns the offered content type preferred by the `Accept` header, or an empty string if none is acceptable.
+// A missing `Accept` header accepts the first offer.
+func negotiateContentType(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	mediaRanges := parseAccept(accept)
+	bestOffer := ""
+	bestQuality := 0.0
+
+	for _, offer := range offers {
+		// The quality of an offer is given by its most specific media range
+		specificity, quality := 0, 0.0
+		for _, mediaRange := range mediaRanges {
+			if s := mediaRange.match(strings.ToLower(offer)); s > specificity {
+				specificity, quality = s, mediaRange.quality
+			}
+		}
+
+		if quality > bestQuality {
+			bestOffer, bestQuality = offer, quality
+		}
+	}
+
+	return bestOffer
+}
+
+// Whether the `Accept-Encoding` header allows the encoding (ex: `gzip`), explicitly or through `*`.
+// Ex: `gzip;q=0, *` refuses gzip.
+func acceptsEncoding(acceptEncoding string, encoding string) bool {
+	specificity, quality := 0, 0.0
+	for _, encodingRange := range parseAccept(acceptEncoding) {
+		if encodingRange.mediaType == encoding {
+			return encodingRange.quality > 0
+		} else if encodingRange.mediaType == "*" {
+			specificity, quality = 1, encodingRange.quality
+		}
+	}
+
+	return specificity > 0 && quality > 0
+}
+
+// Whether the request's Content-Type (parameters like `charset` are ignored) is one of the consumed content types
+func isConsumed(contentType string, consumes []string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, consumed := range consumes {
+		if strings.EqualFold(mediaType, consumed) {
+			return true
+		}
+	}
+
+	return false
+}
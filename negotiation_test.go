@@ -0,0 +1,206 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func TestNegotiateContentType_when_acceptIsEmpty(t *testing.T) {
+	// GIVEN
+	offers := []string{"application/json", "application/xml"}
+
+	// WHEN
+	actual := negotiateContentType("", offers)
+
+	// THEN
+	if actual != "application/json" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/json")
+	}
+}
+
+func TestNegotiateContentType_when_qualityValues(t *testing.T) {
+	// GIVEN
+	offers := []string{"application/json", "application/xml"}
+
+	// WHEN
+	actual := negotiateContentType("application/json;q=0.5, application/*;q=0.8, */*;q=0.1", offers)
+
+	// THEN
+	if actual != "application/xml" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/xml")
+	}
+}
+
+func TestNegotiateContentType_when_notAcceptable(t *testing.T) {
+	// GIVEN
+	offers := []string{"application/json"}
+
+	// WHEN
+	actual := negotiateContentType("application/xml, text/*;q=0.5", offers)
+
+	// THEN
+	if actual != "" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "")
+	}
+}
+
+func TestIsConsumed_when_contentTypeHasParameters(t *testing.T) {
+	// GIVEN
+	contentType := "application/json; charset=utf-8"
+
+	// WHEN
+	actual := isConsumed(contentType, []string{"application/json"})
+
+	// THEN
+	if actual == false {
+		t.Errorf("Actual: '%t', expected: '%t'", actual, true)
+	}
+}
+
+func TestDispatcher_when_error_unsupportedMediaType(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler, WithConsumes("application/json")), nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`<a>1</a>`))
+	request.Header.Set("Content-Type", "application/xml")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusUnsupportedMediaType)
+	}
+}
+
+func TestDispatcher_when_error_notAcceptable(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(200, nil)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithProduces("application/json")), nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusNotAcceptable {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNotAcceptable)
+	}
+}
+
+func TestDispatcher_when_consumedAndAcceptable(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return JsonResponse(200, body)
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler, WithConsumes("application/json"), WithProduces("application/json")), nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"a":1}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/*")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestDispatcher_when_strictAcceptSatisfiable(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(200, nil)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.Produces = []string{"application/json"}
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("Accept", "application/xml;q=0.9, application/json;q=0.5")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestDispatcher_when_error_strictAcceptUnsatisfiable(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(200, nil)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.Produces = []string{"application/json"}
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusNotAcceptable {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNotAcceptable)
+	}
+}
+
+func TestDispatcher_when_strictAcceptRouteProducesOverrides(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return XmlResponse(200, nil)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithProduces("application/xml")), nil)
+	dispatcher.Produces = []string{"application/json"}
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestAcceptsEncoding_when_nominal(t *testing.T) {
+	cases := map[string]bool{
+		"": false,
+		"gzip": true,
+		"deflate, gzip;q=0.5": true,
+		"*": true,
+		"gzip;q=0": false,
+		"gzip;q=0, *": false,
+		"deflate": false,
+	}
+
+	for acceptEncoding, expected := range cases {
+		// GIVEN / WHEN
+		actual := acceptsEncoding(acceptEncoding, "gzip")
+
+		// THEN
+		if actual != expected {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", actual, acceptEncoding, expected)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Returns the value of a `Link` header (RFC 5988) with the `first`, `prev`, `next` and `last` relations of a list,
+// `prev` and `next` being omitted on the first and last pages. The `page` and `per_page` query parameters
+// are added to `base`, whose other query parameters are kept.
+// Ex: `WithHeaders(JsonResponse(200, users), map[string]string{"Link": PaginationLinks("/users", 2, 10, 95)})`
+func PaginationLinks(base string, page int, perPage int, total int) string {
+	if perPage <= 0 {
+		panic(fmt.Sprintf("[PaginationLinks] perPage must be positive but was %d", perPage))
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		panic(fmt.Sprintf("[PaginationLinks] base '%s' is not a valid URL -> '%s'", base, err))
+	}
+
+	lastPage := (total + perPage - 1) / perPage
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	link := func(rel string, target int) string {
+		query := baseURL.Query()
+		query.Set("page", strconv.Itoa(target))
+		query.Set("per_page", strconv.Itoa(perPage))
+
+		targetURL := *baseURL
+		targetURL.RawQuery = query.Encode()
+		return fmt.Sprintf(`<%s>; rel="%s"`, targetURL.String(), rel)
+	}
+
+	links := []string{link("first", 1)}
+	if page > 1 {
+		links = append(links, link("prev", page - 1))
+	}
+	if page < lastPage {
+		links = append(links, link("next", page + 1))
+	}
+	links = append(links, link("last", lastPage))
+
+	return strings.Join(links, ", ")
+}
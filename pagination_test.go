@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestPaginationLinks_when_middlePage(t *testing.T) {
+	// GIVEN
+	base := "https://api.example.com/users?sort=name"
+
+	// WHEN
+	actual := PaginationLinks(base, 3, 10, 95)
+
+	// THEN
+	expected := `<https://api.example.com/users?page=1&per_page=10&sort=name>; rel="first", ` +
+		`<https://api.example.com/users?page=2&per_page=10&sort=name>; rel="prev", ` +
+		`<https://api.example.com/users?page=4&per_page=10&sort=name>; rel="next", ` +
+		`<https://api.example.com/users?page=10&per_page=10&sort=name>; rel="last"`
+	if actual != expected {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, expected)
+	}
+}
+
+func TestPaginationLinks_when_firstAndOnlyPage(t *testing.T) {
+	// GIVEN
+	base := "/users"
+
+	// WHEN
+	actual := PaginationLinks(base, 1, 10, 0)
+
+	// THEN
+	expected := `</users?page=1&per_page=10>; rel="first", </users?page=1&per_page=10>; rel="last"`
+	if actual != expected {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, expected)
+	}
+}
+
+func TestWithHeaders_when_linkHeaderOnJsonResponse(t *testing.T) {
+	// GIVEN
+	links := PaginationLinks("/users", 2, 10, 30)
+	request := httptest.NewRequest(http.MethodGet, "/users?page=2", nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	WithHeaders(JsonResponse(200, []string{}), map[string]string{"Link": links}).write(recorder, request)
+
+	// THEN
+	if actual := recorder.Header().Get("Link"); actual != links {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, links)
+	}
+
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "[]" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "[]")
+	}
+}
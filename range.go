@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Byte range of a `Range` request header, resolved against the resource's size
+type HTTPRange struct {
+	Start int64
+	Length int64
+}
+
+// Value of the `Content-Range` response header. Ex: `bytes 0-499/1234`
+func (r HTTPRange) ContentRange(size int64) string {
+	return "bytes " + strconv.FormatInt(r.Start, 10) + "-" + strconv.FormatInt(r.Start + r.Length - 1, 10) + "/" + strconv.FormatInt(size, 10)
+}
+
+// Parses the `Range` header against a resource of `size` bytes, for handlers serving partial content themselves.
+// Returns nil if the header is absent, or a `416 Range Not Satisfiable` `HTTPError` if it's malformed or if no range
+// overlaps the resource. Ranges starting past the end are skipped, ends past the end are truncated.
+func (h *Http) ParseRange(size int64) ([]HTTPRange, error) {
+	header := h.Request.Header.Get("Range")
+	if header == "" {
+		return nil, nil
+	}
+
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "Invalid range unit")
+	}
+
+	var ranges []HTTPRange
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		dash := strings.Index(spec, "-")
+		if dash < 0 {
+			return nil, NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "Invalid range: " + spec)
+		}
+
+		startText, endText := strings.TrimSpace(spec[:dash]), strings.TrimSpace(spec[dash+1:])
+		var r HTTPRange
+		if startText == "" {
+			// Suffix range, the last N bytes. Ex: `-500`
+			suffixLength, err := strconv.ParseInt(endText, 10, 64)
+			if err != nil || suffixLength < 0 {
+				return nil, NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "Invalid range: " + spec)
+			}
+
+			if suffixLength == 0 || size == 0 {
+				continue
+			}
+
+			if suffixLength > size {
+				suffixLength = size
+			}
+			r.Start = size - suffixLength
+			r.Length = suffixLength
+		} else {
+			start, err := strconv.ParseInt(startText, 10, 64)
+			if err != nil || start < 0 {
+				return nil, NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "Invalid range: " + spec)
+			}
+
+			end := size - 1
+			if endText != "" {
+				end, err = strconv.ParseInt(endText, 10, 64)
+				if err != nil || end < start {
+					return nil, NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "Invalid range: " + spec)
+				}
+
+				if end >= size {
+					end = size - 1
+				}
+			}
+
+			if start >= size {
+				continue
+			}
+			r.Start = start
+			r.Length = end - start + 1
+		}
+
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, NewHTTPError(http.StatusRequestedRangeNotSatisfiable, "Unsatisfiable range: " + header)
+	}
+
+	return ranges, nil
+}
@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"testing"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+)
+
+func parseMockRange(rangeHeader string, size int64) ([]HTTPRange, error) {
+	request := httptest.NewRequest(http.MethodGet, "/file", nil)
+	if rangeHeader != "" {
+		request.Header.Set("Range", rangeHeader)
+	}
+
+	h := &Http{Response: httptest.NewRecorder(), Request: request}
+	return h.ParseRange(size)
+}
+
+func TestHttpParseRange_when_absent(t *testing.T) {
+	// GIVEN
+	rangeHeader := ""
+
+	// WHEN
+	actual, err := parseMockRange(rangeHeader, 1000)
+
+	// THEN
+	if err != nil || actual != nil {
+		t.Errorf("Actual: '%v' '%v', expected: '%v'", actual, err, nil)
+	}
+}
+
+func TestHttpParseRange_when_single(t *testing.T) {
+	// GIVEN
+	rangeHeader := "bytes=0-499"
+
+	// WHEN
+	actual, err := parseMockRange(rangeHeader, 1000)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	expected := []HTTPRange{{Start: 0, Length: 500}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+
+	if actual[0].ContentRange(1000) != "bytes 0-499/1000" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual[0].ContentRange(1000), "bytes 0-499/1000")
+	}
+}
+
+func TestHttpParseRange_when_multiple(t *testing.T) {
+	// GIVEN
+	rangeHeader := "bytes=0-99, 200-, 900-2000"
+
+	// WHEN
+	actual, err := parseMockRange(rangeHeader, 1000)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	expected := []HTTPRange{{Start: 0, Length: 100}, {Start: 200, Length: 800}, {Start: 900, Length: 100}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+}
+
+func TestHttpParseRange_when_suffix(t *testing.T) {
+	// GIVEN
+	rangeHeader := "bytes=-300"
+
+	// WHEN
+	actual, err := parseMockRange(rangeHeader, 1000)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	expected := []HTTPRange{{Start: 700, Length: 300}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+}
+
+func TestHttpParseRange_when_error_invalid(t *testing.T) {
+	for _, rangeHeader := range []string{"items=0-1", "bytes=abc", "bytes=5-1", "bytes=-x", "bytes=--1"} {
+		// GIVEN / WHEN
+		actual, err := parseMockRange(rangeHeader, 1000)
+
+		// THEN
+		var httpError *HTTPError
+		if !errors.As(err, &httpError) || httpError.Code != http.StatusRequestedRangeNotSatisfiable {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", err, rangeHeader, http.StatusRequestedRangeNotSatisfiable)
+		}
+
+		if actual != nil {
+			t.Errorf("Actual: '%v', expected: '%v'", actual, nil)
+		}
+	}
+}
+
+func TestHttpParseRange_when_error_unsatisfiable(t *testing.T) {
+	// GIVEN
+	rangeHeader := "bytes=1000-1100"
+
+	// WHEN
+	_, err := parseMockRange(rangeHeader, 1000)
+
+	// THEN
+	var httpError *HTTPError
+	if !errors.As(err, &httpError) || httpError.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("Actual: '%v', expected: '%v'", err, http.StatusRequestedRangeNotSatisfiable)
+	}
+}
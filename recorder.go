@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Writers knowing whether something was written: `responseRecorder`, `timeoutWriter`
+type statusRecorder interface {
+	written() bool
+}
+
+// Wraps the `http.ResponseWriter` given to `Dispatcher.ServeHTTP()` for recording what was written
+type responseRecorder struct {
+	http.ResponseWriter
+
+	// Zero until the header is written
+	statusCode int
+	bytesWritten int64
+
+	// Set when the handler is invoked if `Dispatcher.ServerTiming` is enabled
+	handlerStart time.Time
+
+	// Set for a HEAD request answered by a GET route: only the status and the headers are sent
+	discardBody bool
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if r.statusCode == 0 {
+		r.statusCode = statusCode
+		if !r.handlerStart.IsZero() {
+			duration := float64(time.Since(r.handlerStart).Microseconds()) / 1000
+			r.Header().Add("Server-Timing", fmt.Sprintf("app;dur=%.3f", duration))
+		}
+	}
+
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.statusCode == 0 {
+		// Like `net/http`, writing without `WriteHeader()` sends a 200
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if r.discardBody {
+		return len(data), nil
+	}
+
+	n, err := r.ResponseWriter.Write(data)
+	r.bytesWritten += int64(n)
+	return n, err
+}
+
+// Gives `http.ResponseController` access to the connection (ex: write deadlines)
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Keeps streaming responses working through the recorder
+func (r *responseRecorder) Flush() {
+	flush(r.ResponseWriter)
+}
+
+// Keeps connection upgrades working through the recorder (ex: websockets), `http.ErrNotSupported` if the wrapped
+// writer can't be hijacked
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, readWriter, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && r.statusCode == 0 {
+		// The connection is the handler's: nothing must be written by the dispatcher afterwards
+		r.statusCode = http.StatusSwitchingProtocols
+	}
+
+	return conn, readWriter, err
+}
+
+// HTTP/2 server push, `http.ErrNotSupported` if the wrapped writer can't push
+func (r *responseRecorder) Push(target string, options *http.PushOptions) error {
+	if pusher, ok := r.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, options)
+	}
+
+	return http.ErrNotSupported
+}
+
+// Status sent to the client, `net/http` sends a 200 if nothing was written
+func (r *responseRecorder) status() int {
+	if r.statusCode == 0 {
+		return http.StatusOK
+	}
+
+	return r.statusCode
+}
+
+// Whether the header was already sent, in which case the status can't be changed anymore
+func (r *responseRecorder) written() bool {
+	return r.statusCode != 0
+}
@@ -0,0 +1,37 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CRUD handlers of a resource, registered by `Routes.Resource()`. The identifier is `Http.PathVariables["id"]`,
+// the request body of `Create()` and `Update()` is read with `Http.RawBody()`.
+type ResourceHandler interface {
+	List(h *Http) HttpResponse
+	Get(h *Http) HttpResponse
+	Create(h *Http) HttpResponse
+	Update(h *Http) HttpResponse
+	Delete(h *Http) HttpResponse
+}
+
+// Registers `GET base`, `GET base/{id}`, `POST base`, `PUT base/{id}` and `DELETE base/{id}`, the options apply to every route
+func (routes Routes) Resource(base string, handler ResourceHandler, options ...RouteOption) Routes {
+	if handler == nil {
+		panic("[Routes#Resource] handler must not be `nil`")
+	}
+
+	// Ex: `/users/` => `/users` and `/users/{id}`
+	collectionPath := strings.TrimSuffix(base, "/")
+	itemPath := collectionPath + "/{id}"
+	if collectionPath == "" {
+		collectionPath = "/"
+	}
+
+	return routes.
+		addRoute(http.MethodGet, collectionPath, handler.List, options).
+		addRoute(http.MethodGet, itemPath, handler.Get, options).
+		addRoute(http.MethodPost, collectionPath, handler.Create, options).
+		addRoute(http.MethodPut, itemPath, handler.Update, options).
+		addRoute(http.MethodDelete, itemPath, handler.Delete, options)
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+)
+
+type mockResourceHandler struct{}
+
+func (mockResourceHandler) List(h *Http) HttpResponse {
+	return TextResponse(http.StatusOK, "list")
+}
+
+func (mockResourceHandler) Get(h *Http) HttpResponse {
+	return TextResponse(http.StatusOK, "get " + h.PathVariables["id"])
+}
+
+func (mockResourceHandler) Create(h *Http) HttpResponse {
+	body, _ := h.RawBody()
+	return TextResponse(http.StatusCreated, "create " + string(body))
+}
+
+func (mockResourceHandler) Update(h *Http) HttpResponse {
+	body, _ := h.RawBody()
+	return TextResponse(http.StatusOK, "update " + h.PathVariables["id"] + " " + string(body))
+}
+
+func (mockResourceHandler) Delete(h *Http) HttpResponse {
+	return TextResponse(http.StatusOK, "delete " + h.PathVariables["id"])
+}
+
+func TestRoutesResource_when_nominal(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().Resource("/users", mockResourceHandler{}), nil)
+	cases := []struct {
+		method string
+		path string
+		body string
+		expectedStatus int
+		expectedBody string
+	}{
+		{http.MethodGet, "/users", "", http.StatusOK, "list"},
+		{http.MethodGet, "/users/42", "", http.StatusOK, "get 42"},
+		{http.MethodPost, "/users", "Alice", http.StatusCreated, "create Alice"},
+		{http.MethodPut, "/users/42", "Bob", http.StatusOK, "update 42 Bob"},
+		{http.MethodDelete, "/users/42", "", http.StatusOK, "delete 42"},
+	}
+
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(c.method, c.path, strings.NewReader(c.body)))
+
+		// THEN
+		if recorder.Code != c.expectedStatus || recorder.Body.String() != c.expectedBody {
+			t.Errorf("Actual: '%d %s' for '%s %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), c.method, c.path, c.expectedStatus, c.expectedBody)
+		}
+	}
+}
+
+func TestRoutesResource_when_trailingSlash(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes().Resource("/users/", mockResourceHandler{})
+
+	// WHEN
+	actual := []string{routes[http.MethodPost][0].GetPath(), routes[http.MethodDelete][0].GetPath()}
+
+	// THEN
+	expected := []string{"/users", "/users/{id}"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+}
@@ -5,25 +5,65 @@
 package rest
 
 import (
+	"context"
 	"net/http"
+	"net/url"
 	"errors"
 	"reflect"
+	"runtime/debug"
 	"io/ioutil"
 	"io"
 	"encoding/json"
 	"encoding/xml"
 	"regexp"
+	"path"
 	"fmt"
+	"sort"
+	"strconv"
 	"time"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"github.com/eau-de-la-seine/golang-logger"
 )
 
-var log *logger.Logger = logger.NewConsoleLogger(logger.LEVEL_DEBUG)
+// Subset of `logger.Logger` used by this package
+type Logger interface {
+	Debug(format string, args ...interface{})
+	Info(format string, args ...interface{})
+}
+
+// Debug messages are hidden by default, see `SetLogger()`
+var log Logger = logger.NewConsoleLogger(logger.LEVEL_INFO)
+
+// Replaces the package's console logger (ex: a file or structured logger), must be called before serving requests
+func SetLogger(l Logger) {
+	if l == nil {
+		panic("[SetLogger] l must not be `nil`")
+	}
+
+	log = l
+}
+
+// Ensures the missing `http.Flusher` warning is only logged once
+var flusherWarning sync.Once
+
+// Streaming responses flush after each write, but some writers (test recorders, buffering middlewares)
+// don't implement `http.Flusher`: in that case flushing is skipped and the body is sent when the handler returns
+func flush(response http.ResponseWriter) {
+	if flusher, ok := response.(http.Flusher); ok {
+		flusher.Flush()
+		return
+	}
+
+	flusherWarning.Do(func() {
+		log.Debug("[flush] %T does not implement http.Flusher, flushing is disabled", response)
+	})
+}
 
 // Code + Data
 type HttpResponse interface {
-	write(response http.ResponseWriter)
+	write(response http.ResponseWriter, request *http.Request)
 }
 
 // HTTP RESPONSE (JSON/XML)
@@ -32,44 +72,107 @@ type ResponseWriter struct {
 	statusCode int
 	responseBody interface{}
 
+	// Can be nil (ex: error responses), so it must only be read: ranged over or looked up, never assigned to
+	customHeaders map[string]string
+
 	// Note: Cannot use json.NewEncoder / xml.NewEncoder signature because Golang does not support covariance
 	marshal func(interface{}) ([]byte, error)
 }
 
-func (r *ResponseWriter) write(response http.ResponseWriter) {
-	response.Header().Set("Content-Type", r.contentType)
+func (r *ResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	// Marshalling first, so that the Content-Length is known before writing the header
+	marshallizedResponse, marshalErr := r.marshal(r.responseBody)
+	if marshalErr != nil {
+		log.Debug("[ResponseWriter#write] marshal => %s", marshalErr.Error())
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for name, value := range r.customHeaders {
+		response.Header().Set(name, value)
+	}
+
+	// A Content-Type set beforehand (by the custom headers or the handler) takes precedence
+	if response.Header().Get("Content-Type") == "" {
+		response.Header().Set("Content-Type", responseContentType(request, r.contentType))
+	}
+
+	if dispatcher, ok := request.Context().Value(dispatcherContextKey).(*Dispatcher); ok && dispatcher.ResponseTransform != nil {
+		marshallizedResponse = dispatcher.ResponseTransform(response.Header().Get("Content-Type"), marshallizedResponse)
+	}
+	response.Header().Set("Content-Length", strconv.Itoa(len(marshallizedResponse)))
 
 	response.WriteHeader(r.statusCode)
 
-	if marshallizedResponse, marshalErr := r.marshal(r.responseBody); marshalErr == nil {
-		// Write HTTP response
-		if _, err := response.Write(marshallizedResponse); err != nil {
-			log.Debug("[ResponseWriter#write] response.Write => %s", err.Error())
-		}
-	} else {
-		log.Debug("[ResponseWriter#write] marshal => %s", marshalErr.Error())
+	// Write HTTP response
+	if _, err := response.Write(marshallizedResponse); err != nil {
+		log.Debug("[ResponseWriter#write] response.Write => %s", err.Error())
+	}
+}
+
+// Content-Type of a JSON/XML response, replaced by the route's `WithDefaultContentType()` if any (ex: `application/vnd.api+json`).
+// The other formats (ex: CSV, YAML) keep theirs.
+func responseContentType(request *http.Request, contentType string) string {
+	if contentType != "application/json" && contentType != "application/xml" {
+		return contentType
+	}
+
+	if defaultContentType, ok := request.Context().Value(defaultContentTypeContextKey).(string); ok {
+		return defaultContentType
 	}
+
+	return contentType
 }
 
 // HTTP RESPONSE (FILE)
+
+// Files of unknown length up to this size are buffered for sending a Content-Length instead of chunks
+const fileBufferThreshold = 4 << 10
+
 type FileResponseWriter struct {
 	contentType string
 	statusCode int
 	contentLength int
 	file io.Reader
 	contentDisposition string
+
+	// Can be nil, see `ResponseWriter.customHeaders`
+	customHeaders map[string]string
 }
 
-func (r *FileResponseWriter) write(response http.ResponseWriter) {
-	if r.contentLength > 0 {
-		response.Header().Set("Content-Length", string(r.contentLength))
+func (r *FileResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	for name, value := range r.customHeaders {
+		response.Header().Set(name, value)
 	}
 
 	response.Header().Set("Content-Disposition", r.contentDisposition)
 	response.Header().Set("Content-Type", r.contentType)
 
+	// An unknown length (zero or negative) is measured by buffering small files, larger ones are left to the
+	// server which chunks the response
+	var head []byte
+	if r.contentLength > 0 {
+		response.Header().Set("Content-Length", strconv.Itoa(r.contentLength))
+	} else {
+		head = make([]byte, fileBufferThreshold + 1)
+		n, readErr := io.ReadFull(r.file, head)
+		head = head[:n]
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			response.Header().Set("Content-Length", strconv.Itoa(n))
+		} else if readErr != nil {
+			log.Debug("[FileResponseWriter#write] ReadFull => %s", readErr.Error())
+			response.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
 	response.WriteHeader(r.statusCode)
 
+	if _, writeErr := response.Write(head); writeErr != nil {
+		log.Debug("[FileResponseWriter#write] Write => %s", writeErr.Error())
+		return
+	}
+
 	if _, copyErr := io.Copy(response, r.file); copyErr != nil {
 		log.Debug("[FileResponseWriter#write] Copy => %s", copyErr.Error())
 	}
@@ -77,10 +180,29 @@ func (r *FileResponseWriter) write(response http.ResponseWriter) {
 
 
 // HTTP RESPONSE (NO-CONTENT)
-type NoContentResponseWriter struct {}
+type NoContentResponseWriter struct {
+	// `204 No Content` unless set (ex: `TooManyRequestsResponse()` without body)
+	statusCode int
+
+	// Can be nil, see `ResponseWriter.customHeaders`
+	customHeaders map[string]string
+}
+
+func (r *NoContentResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	for name, value := range r.customHeaders {
+		response.Header().Set(name, value)
+	}
 
-func (r *NoContentResponseWriter) write(response http.ResponseWriter) {
-	response.WriteHeader(http.StatusNoContent)
+	if r.statusCode == 0 {
+		response.WriteHeader(http.StatusNoContent)
+	} else {
+		response.WriteHeader(r.statusCode)
+	}
+}
+
+// Statuses which must not have a body (nor a Content-Length): 1xx, `204 No Content` and `304 Not Modified`
+func isBodylessStatus(statusCode int) bool {
+	return (statusCode >= 100 && statusCode < 200) || statusCode == http.StatusNoContent || statusCode == http.StatusNotModified
 }
 
 // HTTP RESPONSE (TEXT)
@@ -90,8 +212,9 @@ type TextResponseWriter struct {
 	responseBody string
 }
 
-func (r *TextResponseWriter) write(response http.ResponseWriter) {
+func (r *TextResponseWriter) write(response http.ResponseWriter, request *http.Request) {
 	response.Header().Set("Content-Type", "text/plain")
+	response.Header().Set("Content-Length", strconv.Itoa(len(r.responseBody)))
 
 	response.WriteHeader(r.statusCode)
 
@@ -100,6 +223,61 @@ func (r *TextResponseWriter) write(response http.ResponseWriter) {
 	}
 }
 
+// HTTP RESPONSE (REDIRECT)
+type RedirectResponseWriter struct {
+	statusCode int
+	location string
+}
+
+func (r *RedirectResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	response.Header().Set("Location", r.location)
+	response.WriteHeader(r.statusCode)
+}
+
+// HTTP RESPONSE (HTTP.HANDLER)
+type HandlerResponseWriter struct {
+	handler http.Handler
+}
+
+func (r *HandlerResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	r.handler.ServeHTTP(response, request)
+}
+
+// HTTP RESPONSE (LAST-MODIFIED DECORATOR)
+type LastModifiedResponseWriter struct {
+	lastModified time.Time
+	response HttpResponse
+}
+
+func (r *LastModifiedResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	// HTTP dates have a precision of one second
+	lastModified := r.lastModified.UTC().Truncate(time.Second)
+	response.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if request.Method == http.MethodGet || request.Method == http.MethodHead {
+		if since, err := http.ParseTime(request.Header.Get("If-Modified-Since")); err == nil && !lastModified.After(since) {
+			response.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	r.response.write(response, request)
+}
+
+// HTTP RESPONSE (HEADERS DECORATOR)
+type HeadersResponseWriter struct {
+	headers map[string]string
+	response HttpResponse
+}
+
+func (r *HeadersResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	for name, value := range r.headers {
+		response.Header().Set(name, value)
+	}
+
+	r.response.write(response, request)
+}
+
 // IMPLEMENTATIONS
 
 func JsonResponse(statusCode int, responseBody interface{}) HttpResponse {
@@ -118,12 +296,57 @@ func XmlResponse(statusCode int, responseBody interface{}) HttpResponse {
 		marshal: xml.Marshal}
 }
 
+// `201 Created` with the `Location` of the new resource and its JSON representation, `customHeaders` can be nil
+func CreatedResponse(location string, responseBody interface{}, customHeaders map[string]string) HttpResponse {
+	headers := make(map[string]string, len(customHeaders) + 1)
+	for name, value := range customHeaders {
+		headers[name] = value
+	}
+	headers["Location"] = location
+
+	return &ResponseWriter{
+		contentType: "application/json",
+		statusCode: http.StatusCreated,
+		responseBody: responseBody,
+		customHeaders: headers,
+		marshal: json.Marshal}
+}
+
+// `429 Too Many Requests` with the `Retry-After` (in seconds, rounded up), `X-RateLimit-Limit` and `X-RateLimit-Remaining` headers.
+// `responseBody` is optional and sent as JSON.
+func TooManyRequestsResponse(retryAfter time.Duration, limit, remaining int, responseBody ...interface{}) HttpResponse {
+	headers := map[string]string{
+		"Retry-After": retryAfterSeconds(retryAfter),
+		"X-RateLimit-Limit": strconv.Itoa(limit),
+		"X-RateLimit-Remaining": strconv.Itoa(remaining)}
+
+	if len(responseBody) == 0 {
+		return &NoContentResponseWriter{
+			statusCode: http.StatusTooManyRequests,
+			customHeaders: headers}
+	}
+
+	return &ResponseWriter{
+		contentType: "application/json",
+		statusCode: http.StatusTooManyRequests,
+		responseBody: responseBody[0],
+		customHeaders: headers,
+		marshal: json.Marshal}
+}
+
+// `Retry-After` header value, in seconds rounded up
+func retryAfterSeconds(retryAfter time.Duration) string {
+	return strconv.FormatInt(int64((retryAfter + time.Second - 1) / time.Second), 10)
+}
+
 type ErrorResponse struct {
 	// time.Now().Format(time.RFC3339)
 	Date string
 	Message string
 	Method string
 	Path string
+	// See `RequestID()`, omitted if empty
+	RequestID string `json:",omitempty" xml:",omitempty"`
 }
 
 func JsonErrorResponse(statusCode int, request *http.Request, message string) HttpResponse {
@@ -131,7 +354,8 @@ func JsonErrorResponse(statusCode int, request *http.Request, message string) Ht
 		Date: time.Now().Format(time.RFC3339),
 		Message: message,
 		Method: request.Method,
-		Path: request.URL.Path}
+		Path: request.URL.Path,
+		RequestID: RequestID(request)}
 
 	return &ResponseWriter{
 		contentType: "application/json",
@@ -145,7 +369,8 @@ func XmlErrorResponse(statusCode int, request *http.Request, message string) Htt
 		Date: time.Now().Format(time.RFC3339),
 		Message: message,
 		Method: request.Method,
-		Path: request.URL.Path}
+		Path: request.URL.Path,
+		RequestID: RequestID(request)}
 
 	return &ResponseWriter{
 		contentType: "application/xml",
@@ -154,17 +379,39 @@ func XmlErrorResponse(statusCode int, request *http.Request, message string) Htt
 		marshal: xml.Marshal}
 }
 
-func FileResponse(statusCode int, contentType string, contentDisposition string, contentLength int, file io.Reader) HttpResponse {
+// `contentDisposition` is sent as is, build it with `ContentDisposition()` when it carries a filename.
+// `customHeaders` are optional (ex: `Cache-Control`, `ETag`), the Content-Type, Content-Disposition and Content-Length take precedence.
+func FileResponse(statusCode int, contentType string, contentDisposition string, contentLength int, file io.Reader, customHeaders ...map[string]string) HttpResponse {
 	return &FileResponseWriter{
 		contentType: contentType,
 		contentDisposition: contentDisposition,
 		contentLength: contentLength,
 		statusCode: statusCode,
-		file: file}
+		file: file,
+		customHeaders: mergeHeaders(customHeaders)}
+}
+
+// `customHeaders` are optional (ex: `Location`)
+func NoContentResponse(customHeaders ...map[string]string) HttpResponse {
+	return &NoContentResponseWriter{customHeaders: mergeHeaders(customHeaders)}
 }
 
-func NoContentResponse() HttpResponse {
-	return &NoContentResponseWriter{}
+// Later maps win, nil if there's no header
+func mergeHeaders(headerMaps []map[string]string) map[string]string {
+	if len(headerMaps) == 0 {
+		return nil
+	} else if len(headerMaps) == 1 {
+		return headerMaps[0]
+	}
+
+	headers := make(map[string]string)
+	for _, headerMap := range headerMaps {
+		for name, value := range headerMap {
+			headers[name] = value
+		}
+	}
+
+	return headers
 }
 
 func TextResponse(statusCode int, responseBody string) HttpResponse {
@@ -173,12 +420,69 @@ func TextResponse(statusCode int, responseBody string) HttpResponse {
 		responseBody: responseBody}
 }
 
+// Redirects to `location` without body. Allowed statuses:
+// * `301 Moved Permanently`, `302 Found`: clients may change a POST into a GET (most do)
+// * `303 See Other`: the client follows with a GET (ex: after a form POST)
+// * `307 Temporary Redirect`, `308 Permanent Redirect`: the client repeats the same method and body
+func RedirectResponse(statusCode int, location string) HttpResponse {
+	switch statusCode {
+		case http.StatusMovedPermanently,
+			http.StatusFound,
+			http.StatusSeeOther,
+			http.StatusTemporaryRedirect,
+			http.StatusPermanentRedirect:
+		default:
+			panic(fmt.Sprintf("[RedirectResponse] statusCode must be 301, 302, 303, 307 or 308 but was %d", statusCode))
+	}
+
+	return &RedirectResponseWriter{
+		statusCode: statusCode,
+		location: location}
+}
+
+// Delegates the rest of the request to a standard handler. Ex: `HandlerResponse(http.FileServer(dir))`
+func HandlerResponse(handler http.Handler) HttpResponse {
+	if handler == nil {
+		panic("[HandlerResponse] handler must not be `nil`")
+	}
+
+	return &HandlerResponseWriter{handler: handler}
+}
+
+// Sets the `Last-Modified` header and answers `304 Not Modified` when the request's `If-Modified-Since` is not older
+func WithLastModified(response HttpResponse, lastModified time.Time) HttpResponse {
+	if response == nil {
+		panic("[WithLastModified] response must not be `nil`")
+	}
+
+	return &LastModifiedResponseWriter{
+		lastModified: lastModified,
+		response: response}
+}
+
+// Adds headers to any response. Ex: `WithHeaders(JsonResponse(200, users), map[string]string{"Link": links})`
+func WithHeaders(response HttpResponse, headers map[string]string) HttpResponse {
+	if response == nil {
+		panic("[WithHeaders] response must not be `nil`")
+	}
+
+	return &HeadersResponseWriter{
+		headers: headers,
+		response: response}
+}
+
 type PathVariable struct {
 	// Index of the pathVariable starting from zero. Ex: /{v0}/{v1}/path2/{v3}/path4
 	pathIndex int
 
 	// Variable name. Ex: v0, v1, v3
 	variableName string
+
+	// Trailing `{name...}`, its value is the remainder of the path, slashes included
+	catchAll bool
+
+	// Type constraining the value, ex: `int` for `{id:int}`. See `pathVariableTypePatterns`.
+	variableType string
 }
 
 type Http struct {
@@ -186,18 +490,171 @@ type Http struct {
 	Request *http.Request
 	PathVariables map[string]string
 	// TODO: For Golang 2, add generic `RequestBody T` here
+
+	// Cached by `RawBody()`, the request body can only be read once
+	rawBody []byte
+	rawBodyErr error
+	rawBodyRead bool
+
+	// Queued by `AddCookie()`
+	cookies []*http.Cookie
+
+	// Parsed once by `queryValues()`
+	query url.Values
+
+	// See `Dispatcher.MultipartMemory`
+	multipartMemory int64
+}
+
+func (h *Http) queryValues() url.Values {
+	if h.query == nil {
+		h.query = h.Request.URL.Query()
+	}
+
+	return h.query
+}
+
+// Returns the first value of the query parameter, or an empty string if absent. Ex: `?id=1&id=2` => "1"
+func (h *Http) QueryParam(name string) string {
+	return h.queryValues().Get(name)
+}
+
+// Like `QueryParam()` but returns `defaultValue` if the parameter is absent (an empty value is returned as is)
+func (h *Http) QueryParamDefault(name string, defaultValue string) string {
+	if values, exists := h.queryValues()[name]; exists && len(values) > 0 {
+		return values[0]
+	}
+
+	return defaultValue
+}
+
+// Returns the query parameter as an integer, the error is a `400 Bad Request` `HTTPError` if it's absent or malformed
+func (h *Http) QueryParamInt(name string) (int, error) {
+	values, exists := h.queryValues()[name]
+	if !exists || len(values) == 0 {
+		return 0, BadRequest(fmt.Sprintf("Query parameter '%s' is required", name))
+	}
+
+	value, err := strconv.Atoi(values[0])
+	if err != nil {
+		return 0, BadRequest(fmt.Sprintf("Query parameter '%s' must be an integer but was '%s'", name, values[0]))
+	}
+
+	return value, nil
+}
+
+// Returns the path variable as an `int`, the error is a `PathVariableError` (answered with a `400`) if it's malformed
+func (h *Http) PathInt(name string) (int, error) {
+	value, err := strconv.Atoi(h.PathVariables[name])
+	if err != nil {
+		return 0, &PathVariableError{Name: name, Type: "int", Value: h.PathVariables[name]}
+	}
+
+	return value, nil
+}
+
+// Same as `PathInt()` but panics with the `PathVariableError`, the dispatcher answers it with a `400 Bad Request`
+// instead of calling `Dispatcher.PanicHandler`
+func (h *Http) MustPathInt(name string) int {
+	value, err := h.PathInt(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// Same as `QueryParam()`
+func (h *Http) Query(name string) string {
+	return h.QueryParam(name)
+}
+
+// Returns every value of the query parameter in order, or nil if absent. Ex: `?id=1&id=2` => ["1", "2"]
+func (h *Http) QueryAll(name string) []string {
+	return h.queryValues()[name]
+}
+
+// Whether the request's context is done (client disconnected, deadline exceeded), long handlers can check it to bail early
+func (h *Http) Canceled() bool {
+	return h.Request.Context().Err() != nil
+}
+
+// Context of the request, canceled when the client disconnects or the handler's deadline expires. See `Deadline()`.
+func (h *Http) Context() context.Context {
+	return h.Request.Context()
+}
+
+// Deadline of the request's context (see `WithTimeout()` and `Dispatcher.HandlerTimeout`), ok is false without deadline.
+// Calls to other services should not outlive it. Ex: `ctx, cancel := context.WithDeadline(ctx, deadline)`
+func (h *Http) Deadline() (deadline time.Time, ok bool) {
+	return h.Request.Context().Deadline()
+}
+
+// For handlers writing to `Response` themselves (ex: streaming): if nothing was written yet, writes a
+// `JsonErrorResponse`, otherwise the status is already sent so the stream is aborted (the client sees a truncated response).
+// The handler should return nil afterwards.
+func (h *Http) WriteError(statusCode int, message string) {
+	if recorder, ok := h.Response.(statusRecorder); ok && recorder.written() {
+		log.Debug("[Http#WriteError] Response already started, aborting => Method: '%s' | Path: '%s' | Error: %d %s",
+			h.Request.Method,
+			h.Request.URL.Path,
+			statusCode,
+			message)
+		panic(http.ErrAbortHandler)
+	}
+
+	JsonErrorResponse(statusCode, h.Request, message).write(h.Response, h.Request)
+}
+
+// Queues a `Set-Cookie` header, applied when the returned `HttpResponse` is written
+func (h *Http) AddCookie(cookie *http.Cookie) {
+	if cookie == nil {
+		panic("[Http#AddCookie] cookie must not be `nil`")
+	}
+
+	h.cookies = append(h.cookies, cookie)
+}
+
+// Returns the raw request body, for handlers which don't declare a request body parameter (ex: `Routes.ANY`)
+func (h *Http) RawBody() ([]byte, error) {
+	if !h.rawBodyRead {
+		h.rawBody, h.rawBodyErr = ioutil.ReadAll(h.Request.Body)
+		h.rawBodyRead = true
+	}
+
+	return h.rawBody, h.rawBodyErr
 }
 
 type CustomHandler interface {
 	GetRegexPath() *regexp.Regexp
+	// Path given at registration, empty for handlers which don't use the path grammar (ex: built manually)
+	GetPath() string
 	GetRequestBodyType() reflect.Type
 	GetPathVariableNames() []PathVariable
 	HasRequestBody() bool
+	// Content types accepted in the request body, nil accepts everything
+	GetConsumes() []string
+	// Content types the route can respond with, nil satisfies every `Accept` header
+	GetProduces() []string
+	// Human metadata, for introspection
+	GetDescription() string
+	GetTags() []string
+	// Handler deadline, zero falls back to `Dispatcher.HandlerTimeout`
+	GetTimeout() time.Duration
+	// Executed after the dispatcher's pre-filters, can be nil
+	GetFilters() []FilterFunc
+	// Host the route is bound to (ex: `api.example.com`, `*.example.com`), empty matches every host
+	GetHost() string
+	// The request body is streamed by the handler, see `WithRawBody()`
+	HasRawBody() bool
 	// TODO: For Golang 2, replace inputs type by `rest.Http`
-	WriteHttpResponse(response http.ResponseWriter, inputs []reflect.Value)
+	WriteHttpResponse(response http.ResponseWriter, request *http.Request, inputs []reflect.Value)
 }
 
 type CustomHandlerImpl struct {
+	path string
+
+	// Compiled with `defaultPathVariablePattern`, see `Dispatcher.PathVariablePattern`
 	regexPath *regexp.Regexp
 
 	// Can be nil if no data
@@ -210,43 +667,158 @@ type CustomHandlerImpl struct {
 	// Signature of the handler must be:
 	// 1. rest.Http (contains response, request, pathVariables)
 	// 2. Object (HTTP Request Body generated by JSON or XML), optional
-	// Return an `rest.HttpResponse`
+	// Return an `rest.HttpResponse` or a `string` (sent as a 200 `TextResponse`), optionally followed by an `error` (see `errorResponse()`),
+	// or `(interface{}, int)` (sent as a `JsonResponse`)
 	handlerValue reflect.Value
+
+	// Can be nil, set by `WithConsumes()` and `WithProduces()`
+	consumes []string
+	produces []string
+
+	// Set by `WithDescription()` and `WithTags()`
+	description string
+	tags []string
+
+	// Set by `WithDefaultContentType()`
+	defaultContentType string
+
+	// Set by `WithTimeout()`
+	timeout time.Duration
+
+	// Set by `WithRawBody()`
+	rawBody bool
+
+	// Set by `WithFilters()`
+	filters []FilterFunc
+
+	// Set by `WithHost()` or `Routes.Host()`
+	host string
+}
+
+// Optional route configuration, passed to `Routes.GET()`, `Routes.POST()`, etc.
+type RouteOption func(*CustomHandlerImpl)
+
+// Requests with a body whose Content-Type isn't listed are rejected with `415 Unsupported Media Type`
+func WithConsumes(contentTypes ...string) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.consumes = append(h.consumes, contentTypes...)
+	}
+}
+
+// Requests whose `Accept` header can't be satisfied by the listed content types are rejected with `406 Not Acceptable`
+func WithProduces(contentTypes ...string) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.produces = append(h.produces, contentTypes...)
+	}
+}
+
+// Human description of the route, surfaced by `Routes.List()`
+func WithDescription(description string) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.description = description
+	}
+}
+
+// Tags grouping the route, surfaced by `Routes.List()`
+func WithTags(tags ...string) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.tags = append(h.tags, tags...)
+	}
+}
+
+// Content-Type of the route's JSON/XML responses (ex: `application/vnd.api+json`), unless the handler sets one
+func WithDefaultContentType(contentType string) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.defaultContentType = contentType
+	}
+}
+
+// Deadline of the route's handler, overriding `Dispatcher.HandlerTimeout`. See `Dispatcher.HandlerTimeout`.
+func WithTimeout(timeout time.Duration) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.timeout = timeout
+	}
+}
+
+// Filters of the route (ex: authentication), executed after the dispatcher's pre-filters and before the handler
+func WithFilters(filters ...FilterFunc) RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.filters = append(h.filters, filters...)
+	}
+}
+
+// The request body is left untouched for the handler to stream from `Http.Request.Body` (ex: large uploads):
+// `Dispatcher.MaxBodySize` doesn't apply, the handler bounds what it reads. The handler must only take a `*rest.Http` parameter.
+func WithRawBody() RouteOption {
+	return func(h *CustomHandlerImpl) {
+		h.rawBody = true
+	}
+}
+
+// Panics if the path or the handler is invalid, see `Routes.AddRouteE()` for an error instead
+func NewCustomHandlerImpl(httpMethod string, path string, handlerFunction interface{}, options ...RouteOption) CustomHandler {
+	handler, err := newCustomHandlerImpl(httpMethod, path, handlerFunction, options)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return handler
 }
 
-func NewCustomHandlerImpl(httpMethod string, path string, handlerFunction interface{}) CustomHandler {
+func newCustomHandlerImpl(httpMethod string, path string, handlerFunction interface{}, options []RouteOption) (*CustomHandlerImpl, error) {
 	log.Debug("[NewCustomHandlerImpl] Method: '%s' | Path: '%s'", httpMethod, path)
 
 	if handlerFunction == nil {
-		panic("[NewCustomHandlerImpl] handler must not be `nil`")
+		return nil, errors.New("[NewCustomHandlerImpl] handler must not be `nil`")
 	}
 
-	assertValidPath(path)
+	if err := checkPath(path); err != nil {
+		return nil, err
+	}
 
 	// Validate Handler
 	handlerFunctionType := reflect.TypeOf(handlerFunction)
-	validateHandler(httpMethod, handlerFunctionType)
+	if err := checkHandler(httpMethod, handlerFunctionType); err != nil {
+		return nil, err
+	}
 
 	// Initialization
 	obj := new(CustomHandlerImpl)
 	obj.pathVariableNames = extractPathVariableNames(path)
-	obj.regexPath = toRegexPath(path)
+	obj.path = path
+	obj.regexPath = toRegexPath(path, defaultPathVariablePattern)
 	
 	// Type of param n°2 (Request body type)
 	if handlerFunctionType.NumIn() == 2 {
 		// Getting the underlying type of pointer-type (ex: *MyRequestBody => MyRequestBody)
 		obj.requestBodyType = handlerFunctionType.In(1).Elem()
+		if obj.requestBodyType.Kind() == reflect.Struct {
+			// Warming the schema cache at registration rather than on the first request
+			schemaOf(obj.requestBodyType)
+		}
 	}
 
 	obj.handlerValue = reflect.ValueOf(handlerFunction)
 
-	return obj
+	for _, option := range options {
+		option(obj)
+	}
+
+	if obj.rawBody && obj.requestBodyType != nil {
+		return nil, errors.New("[NewCustomHandlerImpl] `WithRawBody()` handler must only take a `*rest.Http` parameter")
+	}
+
+	return obj, nil
 }
 
 func (h *CustomHandlerImpl) GetRegexPath() *regexp.Regexp {
 	return h.regexPath
 }
 
+func (h *CustomHandlerImpl) GetPath() string {
+	return h.path
+}
+
 func (h *CustomHandlerImpl) GetPathVariableNames() []PathVariable {
 	return h.pathVariableNames
 }
@@ -259,10 +831,123 @@ func (h *CustomHandlerImpl) GetRequestBodyType() reflect.Type {
 	return h.requestBodyType
 }
 
-func (h *CustomHandlerImpl) WriteHttpResponse(response http.ResponseWriter, inputs []reflect.Value) {
-	if impl, ok := h.handlerValue.Call(inputs)[0].Interface().(HttpResponse); ok {
-		impl.write(response)
+func (h *CustomHandlerImpl) GetConsumes() []string {
+	return h.consumes
+}
+
+func (h *CustomHandlerImpl) GetProduces() []string {
+	return h.produces
+}
+
+func (h *CustomHandlerImpl) GetDescription() string {
+	return h.description
+}
+
+func (h *CustomHandlerImpl) GetTags() []string {
+	return h.tags
+}
+
+func (h *CustomHandlerImpl) GetTimeout() time.Duration {
+	return h.timeout
+}
+
+func (h *CustomHandlerImpl) GetFilters() []FilterFunc {
+	return h.filters
+}
+
+func (h *CustomHandlerImpl) GetHost() string {
+	return h.host
+}
+
+func (h *CustomHandlerImpl) HasRawBody() bool {
+	return h.rawBody
+}
+
+// Checks the inputs the way `reflect.Value.Call()` does, whose panic would be cryptic (ex: `reflect: Call using *rest.A as type *rest.B`).
+// Checking beforehand rather than recovering keeps the stack trace of the handler's own panics.
+func (h *CustomHandlerImpl) checkInputs(request *http.Request, inputs []reflect.Value) error {
+	handlerType := h.handlerValue.Type()
+	matches := len(inputs) == handlerType.NumIn()
+	inputTypes := make([]string, len(inputs))
+	for i, input := range inputs {
+		if !input.IsValid() {
+			inputTypes[i] = "<invalid>"
+			matches = false
+			continue
+		}
+
+		inputTypes[i] = input.Type().String()
+		if matches && !input.Type().AssignableTo(handlerType.In(i)) {
+			matches = false
+		}
+	}
+
+	if matches {
+		return nil
+	}
+
+	return fmt.Errorf("[CustomHandlerImpl#WriteHttpResponse] Handler of route '%s %s' called with (%s), expected signature: %s",
+		request.Method,
+		h.path,
+		strings.Join(inputTypes, ", "),
+		handlerType.String())
+}
+
+func (h *CustomHandlerImpl) WriteHttpResponse(response http.ResponseWriter, request *http.Request, inputs []reflect.Value) {
+	if err := h.checkInputs(request, inputs); err != nil {
+		errorResponse(request, err).write(response, request)
+		return
+	}
+
+	outputs := h.handlerValue.Call(inputs)
+	impl, _ := outputs[0].Interface().(HttpResponse)
+
+	// Only the handler's own responses get the route's default Content-Type, never the framework's error responses
+	handlerResponse := true
+
+	// Handlers returning a bare `string`
+	if outputs[0].Kind() == reflect.String {
+		impl = TextResponse(http.StatusOK, outputs[0].String())
+	}
+
+	// Handlers with the `(interface{}, int)` signature
+	if len(outputs) == 2 && outputs[1].Kind() == reflect.Int {
+		if statusCode := int(outputs[1].Int()); isBodylessStatus(statusCode) {
+			impl = &NoContentResponseWriter{statusCode: statusCode}
+		} else {
+			impl = JsonResponse(statusCode, outputs[0].Interface())
+		}
+	}
+
+	// Handlers with the `(rest.HttpResponse, error)` signature
+	if len(outputs) == 2 && outputs[1].Kind() != reflect.Int {
+		if err, _ := outputs[1].Interface().(error); err != nil {
+			impl = errorResponse(request, err)
+			handlerResponse = false
+		}
+	}
+
+	if impl == nil {
+		return
+	}
+
+	// Cookies must be set before the response writes its header
+	for _, cookie := range inputs[0].Interface().(*Http).cookies {
+		http.SetCookie(response, cookie)
+	}
+
+	// Through the context, so that it reaches the JSON/XML responses wrapped by decorators (ex: `WithHeaders()`)
+	if handlerResponse && h.defaultContentType != "" {
+		request = request.WithContext(context.WithValue(request.Context(), defaultContentTypeContextKey, h.defaultContentType))
+	}
+
+	if dispatcher, ok := request.Context().Value(dispatcherContextKey).(*Dispatcher); ok {
+		for _, middleware := range dispatcher.ResponseMiddleware {
+			impl = middleware(impl)
+		}
 	}
+
+	impl.write(response, request)
 }
 
 // Map of HttpMethod/CustomHandlers
@@ -272,39 +957,155 @@ func NewRoutes() Routes {
 	return make(Routes, 0)
 }
 
-// Notes:
-// * Don't need to check httpMethod
-// * path, handler will be checked in `NewCustomHandlerImpl()`
-func (routes Routes) addRoute(httpMethod string, path string, handler interface{}) Routes {
-	if _, exists := routes[httpMethod]; !exists {
-		routes[httpMethod] = make([]CustomHandler, 0)
+// Notes:
+// * Don't need to check httpMethod
+// * path, handler will be checked in `NewCustomHandlerImpl()`
+// * When several routes of a method match a path, the first registered wins (ex: `/users/me` must be registered
+//   before `/users/{id}`)
+func (routes Routes) addRoute(httpMethod string, path string, handler interface{}, options []RouteOption) Routes {
+	if _, exists := routes[httpMethod]; !exists {
+		routes[httpMethod] = make([]CustomHandler, 0)
+	}
+
+	routes[httpMethod] = append(
+		routes[httpMethod],
+		NewCustomHandlerImpl(httpMethod, path, handler, options...))
+
+	return routes
+}
+
+// Registers a route like `GET()`, `POST()`... but returns an error instead of panicking if the path or the handler
+// is invalid (ex: routes loaded from plugins). Nothing is registered on error.
+func (routes Routes) AddRouteE(httpMethod string, path string, handler interface{}, options ...RouteOption) error {
+	customHandler, err := newCustomHandlerImpl(httpMethod, path, handler, options)
+	if err != nil {
+		return err
+	}
+
+	routes[httpMethod] = append(routes[httpMethod], customHandler)
+	return nil
+}
+
+func (routes Routes) GET(path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRoute(http.MethodGet, path, handler, options)
+}
+
+func (routes Routes) POST(path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRoute(http.MethodPost, path, handler, options)
+}
+
+func (routes Routes) PUT(path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRoute(http.MethodPut, path, handler, options)
+}
+
+func (routes Routes) PATCH(path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRoute(http.MethodPatch, path, handler, options)
+}
+
+func (routes Routes) DELETE(path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRoute(http.MethodDelete, path, handler, options)
+}
+
+// Conditional registration (ex: debug or admin routes outside production): the route is only registered if `enabled`
+
+func (routes Routes) GETIf(enabled bool, path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRouteIf(enabled, http.MethodGet, path, handler, options)
+}
+
+func (routes Routes) POSTIf(enabled bool, path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRouteIf(enabled, http.MethodPost, path, handler, options)
+}
+
+func (routes Routes) PUTIf(enabled bool, path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRouteIf(enabled, http.MethodPut, path, handler, options)
+}
+
+func (routes Routes) PATCHIf(enabled bool, path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRouteIf(enabled, http.MethodPatch, path, handler, options)
+}
+
+func (routes Routes) DELETEIf(enabled bool, path string, handler interface{}, options ...RouteOption) Routes {
+	return routes.addRouteIf(enabled, http.MethodDelete, path, handler, options)
+}
+
+func (routes Routes) addRouteIf(enabled bool, httpMethod string, path string, handler interface{}, options []RouteOption) Routes {
+	if !enabled {
+		return routes
+	}
+
+	return routes.addRoute(httpMethod, path, handler, options)
+}
+
+// Applies registration functions in order (ex: `users.Register`, `orders.Register`), so that each package registers
+// its own routes
+func (routes Routes) Include(registrations ...func(Routes)) Routes {
+	for _, register := range registrations {
+		if register == nil {
+			panic("[Routes#Include] registration function must not be `nil`")
+		}
+		register(routes)
 	}
 
-	routes[httpMethod] = append(
-		routes[httpMethod],
-		NewCustomHandlerImpl(httpMethod, path, handler))
-
 	return routes
 }
 
-func (routes Routes) GET(path string, handler interface{}) Routes {
-	return routes.addRoute(http.MethodGet, path, handler)
+// Registered route, returned by `Routes.List()`
+type RouteInfo struct {
+	Method string
+	// Registered path, or the regex for routes outside the path grammar
+	Path string
+	Description string
+	Tags []string
 }
 
-func (routes Routes) POST(path string, handler interface{}) Routes {
-	return routes.addRoute(http.MethodPost, path, handler)
-}
+// Lists the registered routes sorted by HTTP method, in registration order for a given method
+func (routes Routes) List() []RouteInfo {
+	httpMethods := make([]string, 0, len(routes))
+	for httpMethod := range routes {
+		httpMethods = append(httpMethods, httpMethod)
+	}
+	sort.Strings(httpMethods)
+
+	routeInfos := make([]RouteInfo, 0)
+	for _, httpMethod := range httpMethods {
+		for _, handler := range routes[httpMethod] {
+			path := handler.GetPath()
+			if path == "" {
+				path = handler.GetRegexPath().String()
+			}
+
+			routeInfos = append(routeInfos, RouteInfo{
+				Method: httpMethod,
+				Path: path,
+				Description: handler.GetDescription(),
+				Tags: handler.GetTags()})
+		}
+	}
 
-func (routes Routes) PUT(path string, handler interface{}) Routes {
-	return routes.addRoute(http.MethodPut, path, handler)
+	return routeInfos
 }
 
-func (routes Routes) PATCH(path string, handler interface{}) Routes {
-	return routes.addRoute(http.MethodPatch, path, handler)
+// HTTP methods registered by `ANY()`
+var anyHttpMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
 }
 
-func (routes Routes) DELETE(path string, handler interface{}) Routes {
-	return routes.addRoute(http.MethodDelete, path, handler)
+// Registers the handler for every HTTP method, the handler must only take a `*rest.Http` parameter
+// because body expectations differ between methods: use `Http.RawBody()` instead
+func (routes Routes) ANY(path string, handler interface{}, options ...RouteOption) Routes {
+	if handlerType := reflect.TypeOf(handler); handlerType != nil && handlerType.Kind() == reflect.Func && handlerType.NumIn() != 1 {
+		panic(fmt.Sprintf("[Routes#ANY] handler must have 1 input parameter but had %d parameters, use `Http.RawBody()` for reading the request body", handlerType.NumIn()))
+	}
+
+	for _, httpMethod := range anyHttpMethods {
+		routes.addRoute(httpMethod, path, handler, options)
+	}
+
+	return routes
 }
 
 type FilterFunc func(http.ResponseWriter, *http.Request) bool
@@ -339,71 +1140,96 @@ func (filters *Filters) AddPostFilter(filter FilterFunc) *Filters {
 	return filters
 }
 
+// Uses the unmarshaler registered for the Content-Type (see `RegisterUnmarshaler()`), JSON by default
 func unmarshal(contentType string, rawData []byte, objectToFill interface{}) error {
-	switch contentType {
-		case "application/xml":
-			return xml.Unmarshal(rawData, objectToFill)
-		default:
-			return json.Unmarshal(rawData, objectToFill)
+	if unmarshaler := lookupUnmarshaler(contentType); unmarshaler != nil {
+		return unmarshaler(rawData, objectToFill)
 	}
+
+	return json.Unmarshal(rawData, objectToFill)
 }
 
+// Whether a handler of this HTTP method may declare a request body: GET, HEAD and OPTIONS never do, every other
+// method may (ex: POST, DELETE, WebDAV's PROPFIND)
 func isHttpMethodBodyable(httpMethod string) bool {
     switch httpMethod {
 		case
-			http.MethodPost,
-			http.MethodPut,
-			http.MethodPatch,
-			http.MethodDelete:
-			return true
+			"",
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodOptions:
+			return false
 	}
 
-	return false
+	return true
 }
 
 // Deprecated: Will be removed with Golang 2's generics
-func validateHandler(httpMethod string, handlerFunctionType reflect.Type) {
-	log.Debug("[validateHandler] httpMethod => '%s' | isHttpMethodBodyable => '%t' | handlerFunctionType => %s",
+func checkHandler(httpMethod string, handlerFunctionType reflect.Type) error {
+	log.Debug("[checkHandler] httpMethod => '%s' | isHttpMethodBodyable => '%t' | handlerFunctionType => %s",
 		httpMethod,
 		isHttpMethodBodyable(httpMethod),
 		handlerFunctionType)
 
 	if handlerFunctionType.Kind() != reflect.Func {
-		panic("[validateHandler] Parameter 'handlerFunctionType' is not a `func`")
+		return errors.New("[checkHandler] Parameter 'handlerFunctionType' is not a `func`")
 	}
-	log.Debug("[validateHandler] NumIn => %d | NumOut => %d", handlerFunctionType.NumIn(), handlerFunctionType.NumOut())
+	log.Debug("[checkHandler] NumIn => %d | NumOut => %d", handlerFunctionType.NumIn(), handlerFunctionType.NumOut())
 
 	numIn := handlerFunctionType.NumIn()
 	if !(numIn == 1 || numIn == 2) {
-		panic(fmt.Sprintf("[validateHandler] Parameter 'handlerFunctionType' must have 1 or 2 input parameters but had %d parameters", numIn))
-	} else if isHttpMethodBodyable(httpMethod) && numIn == 1 {
-		panic(fmt.Sprintf("[validateHandler] Parameter 'handlerFunctionType' for '%s' HTTP method must have 2 parameters", httpMethod))
+		return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' must have 1 or 2 input parameters but had %d parameters", numIn)
 	} else if !isHttpMethodBodyable(httpMethod) && numIn == 2 {
-		panic(fmt.Sprintf("[validateHandler] Parameter 'handlerFunctionType' for '%s' HTTP method must have 1 parameters", httpMethod))
+		return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' for '%s' HTTP method must have 1 parameters", httpMethod)
 	}
 
 	firstParameterType := handlerFunctionType.In(0)
 	httpType := reflect.TypeOf((**Http)(nil)).Elem()
 	if firstParameterType != httpType {
-		panic(fmt.Sprintf("[validateHandler] Parameter 'handlerFunctionType' parameter n°1 type must be 'rest.Http' but was '%s'", firstParameterType))
+		return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' parameter n°1 type must be '*rest.Http' but was '%s'", firstParameterType)
 	}
 
 	if numIn == 2 {
 		secondParameterType := handlerFunctionType.In(1)
-		if secondParameterType.Kind() != reflect.Ptr {
-			panic(fmt.Sprintf("[validateHandler] Parameter 'handlerFunctionType' parameter n°2 type must be a pointer like '*%s' but was '%s'", secondParameterType, secondParameterType))
+		if secondParameterType.Kind() != reflect.Ptr && httpMethod == http.MethodDelete {
+			// Ex: `func(h *rest.Http, id string)`, DELETE requests rarely carry a body
+			return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' parameter n°2 of a DELETE handler is its request body, its type must be a pointer like '*%s' but was '%s'. Without request body, the DELETE handler only takes '*rest.Http': `func(h *rest.Http) rest.HttpResponse` (path variables in `h.PathVariables`)", secondParameterType, secondParameterType)
+		} else if secondParameterType.Kind() != reflect.Ptr {
+			return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' parameter n°2 type must be a pointer like '*%s' but was '%s'", secondParameterType, secondParameterType)
 		}
 	}
 
 	numOut := handlerFunctionType.NumOut()
-	if numOut != 1 {
-		panic(fmt.Sprintf("[validateHandler] Parameter 'handlerFunctionType' must have 1 output parameter but had %d parameter(s)", numOut))
+	if !(numOut == 1 || numOut == 2) {
+		return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' must have 1 or 2 output parameters but had %d parameter(s)", numOut)
 	}
 	returnType := handlerFunctionType.Out(0)
 	httpResponseType := reflect.TypeOf((*HttpResponse)(nil)).Elem()
-	if returnType != httpResponseType {
-		panic(fmt.Sprintf("[validateHandler] Parameter 'handlerFunctionType' return type must be 'rest.HttpResponse' but was '%s'", returnType))
+	stringType := reflect.TypeOf("")
+	interfaceType := reflect.TypeOf((*interface{})(nil)).Elem()
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	intType := reflect.TypeOf(0)
+
+	// `(interface{}, int)`: body and status of a `JsonResponse`
+	if numOut == 2 && returnType == interfaceType {
+		if secondReturnType := handlerFunctionType.Out(1); secondReturnType != intType {
+			return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' return type n°2 must be 'int' after 'interface{}' but was '%s'", secondReturnType)
+		}
+		return nil
+	}
+
+	if returnType != httpResponseType && returnType != stringType {
+		return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' return type must be 'rest.HttpResponse', 'string' or '(interface{}, int)' but was '%s'", returnType)
+	}
+
+	if numOut == 2 {
+		secondReturnType := handlerFunctionType.Out(1)
+		if secondReturnType != errorType {
+			return fmt.Errorf("[checkHandler] Parameter 'handlerFunctionType' return type n°2 must be 'error' but was '%s'", secondReturnType)
+		}
 	}
+
+	return nil
 }
 
 // Deprecated: Will be removed with Golang 2's generics
@@ -422,22 +1248,66 @@ func inputsWithRequestBody(http *Http, requestBody interface{}) []reflect.Value
 // /path1
 // /path1/pa-th-2/3
 // /path1/{pa-th-2}/3
+// /path1/{id:int}/3 (typed, see `pathVariableTypePatterns`)
+// /path1/{rest...} (catch-all, last segment only)
 func isValidPath(path string) (bool, error) {
 	if path == "/" {
 		return true, nil
 	}
 
 	subPathPattern := `[a-z0-9]+(-?[a-z0-9]+)*`
-	pathPattern := fmt.Sprintf(`^(/(({%s})|(%s)))+$`, subPathPattern, subPathPattern)
-	return regexp.MatchString(pathPattern, path)
+	segmentPattern := fmt.Sprintf(`/(({%s(:[a-z]+)?})|(%s))`, subPathPattern, subPathPattern)
+	pathPattern := fmt.Sprintf(`^(%s)*((%s)|(/{%s\.\.\.}))$`, segmentPattern, segmentPattern, subPathPattern)
+	if ok, err := regexp.MatchString(pathPattern, path); !ok {
+		return ok, err
+	}
+
+	// A duplicated variable name would shadow the first value in `Http.PathVariables`
+	variableNames := make(map[string]bool)
+	for _, pathVariable := range extractPathVariableNames(path) {
+		if variableNames[pathVariable.variableName] {
+			return false, fmt.Errorf("path variable '%s' is declared more than once", pathVariable.variableName)
+		}
+		variableNames[pathVariable.variableName] = true
+
+		if _, exists := pathVariableTypePatterns[pathVariable.variableType]; pathVariable.variableType != "" && !exists {
+			return false, fmt.Errorf("path variable '%s' has an unknown type '%s'", pathVariable.variableName, pathVariable.variableType)
+		}
+	}
+
+	return true, nil
 }
 
-func assertValidPath(path string) {
+func checkPath(path string) error {
 	if ok, err := isValidPath(path); !ok {
-		panic(fmt.Sprintf("[assertValidPath] Path '%s' didn't matched regex pattern -> '%s'", path, err))
+		if err != nil {
+			return fmt.Errorf("[checkPath] Path '%s' is invalid -> '%s'", path, err)
+		}
+		return fmt.Errorf("[checkPath] Path '%s' didn't matched regex pattern", path)
+	}
+
+	return nil
+}
+
+// Panicking variant of `checkPath()`
+func assertValidPath(path string) {
+	if err := checkPath(path); err != nil {
+		panic(err.Error())
 	}
 }
 
+// Catch-all marker of a path variable, ex: `{rest...}`
+const catchAllSuffix = "..."
+
+// Separates a path variable's name from its type, ex: `{id:int}`
+const typeSeparator = ":"
+
+// Type of a path variable => Regex matching its value
+var pathVariableTypePatterns = map[string]string{
+	"int": "[0-9]+",
+	"uuid": "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}",
+}
+
 func removeBraces(key string) string {
 	return strings.Replace(
 		strings.Replace(key, "}", "", 1),
@@ -452,9 +1322,15 @@ func extractPathVariableNames(path string) []PathVariable {
 	pathParts := strings.Split(path, separator)
 	for partIndex, partValue := range pathParts {
 		if strings.HasPrefix(partValue, prefix) {
+			variableName := strings.TrimSuffix(removeBraces(partValue), catchAllSuffix)
+			variableName, variableType, _ := strings.Cut(variableName, typeSeparator)
 			extractedPathVariableNames = append(
 				extractedPathVariableNames,
-				PathVariable{pathIndex: partIndex - 1, variableName: removeBraces(partValue)})
+				PathVariable{
+					pathIndex: partIndex - 1,
+					variableName: variableName,
+					catchAll: strings.HasSuffix(partValue, catchAllSuffix + "}"),
+					variableType: variableType})
 		}
 	}
 
@@ -465,31 +1341,87 @@ func extractPathVariableNames(path string) []PathVariable {
 	return extractedPathVariableNames
 }
 
-// This function may need to be optimized, because it will be executed each time a request is received
-func extractPathVariableValues(path string, pathVariables []PathVariable) map[string]string {
+// Executed each time a request is received for a route with path variables, static routes skip it.
+// Values are taken from the named groups of `regexPath` if any (see `toRegexPath()`), otherwise from the path's segments.
+func extractPathVariableValues(regexPath *regexp.Regexp, path string, pathVariables []PathVariable) map[string]string {
 	extractedPathVariableValues := make(map[string]string, 0)
 	separator := "/"
 	pathParts := strings.Split(path, separator)
 
+	var submatches []string
 	for _, pathVariable := range pathVariables {
+		if regexPath != nil {
+			if index := regexPath.SubexpIndex(captureGroupName(pathVariable.variableName)); index > 0 {
+				if submatches == nil {
+					submatches = regexPath.FindStringSubmatch(path)
+				}
+				if submatches != nil {
+					extractedPathVariableValues[pathVariable.variableName] = submatches[index]
+					continue
+				}
+			}
+		}
+		if pathVariable.catchAll {
+			extractedPathVariableValues[pathVariable.variableName] = strings.Join(pathParts[pathVariable.pathIndex + 1:], separator)
+			continue
+		}
 		extractedPathVariableValues[pathVariable.variableName] = pathParts[pathVariable.pathIndex + 1]
 	}
 
 	return extractedPathVariableValues
 }
 
-func toRegexPath(path string) *regexp.Regexp {
-	regexPart := "[a-zA-Z0-9_-]+"
+// Group names only allow letters, digits and underscores, while path variable names have no underscore
+func captureGroupName(variableName string) string {
+	return strings.ReplaceAll(variableName, "-", "_")
+}
+
+// Regex matching a path variable's value
+const defaultPathVariablePattern = "[a-zA-Z0-9_-]+"
+
+// Anchored, so that `/users` matches neither `/users/5` nor `/xusersx`.
+// A catch-all path variable matches the remainder of the path, slashes included, and a typed one its type's pattern.
+// A custom `pathVariablePattern` may match "/" (ex: `.+`), so the segments don't give the values anymore: each variable
+// is then captured by a group named after it.
+func toRegexPath(path string, pathVariablePattern string) *regexp.Regexp {
+	capture := pathVariablePattern != defaultPathVariablePattern
 	regexPathVariableName := regexp.MustCompile("\\{(.+?)\\}")
-	return regexp.MustCompile(regexPathVariableName.ReplaceAllString(path, regexPart))
+	regexPath := regexPathVariableName.ReplaceAllStringFunc(path, func(pathVariable string) string {
+		variableName, pattern := removeBraces(pathVariable), pathVariablePattern
+		if strings.HasSuffix(variableName, catchAllSuffix) {
+			variableName, pattern = strings.TrimSuffix(variableName, catchAllSuffix), "(.+)"
+		} else if name, variableType, typed := strings.Cut(variableName, typeSeparator); typed {
+			variableName, pattern = name, pathVariableTypePatterns[variableType]
+		}
+
+		if capture {
+			return "(?P<" + captureGroupName(variableName) + ">" + pattern + ")"
+		}
+		return pattern
+	})
+	return regexp.MustCompile("^" + regexPath + "$")
 }
 
-func toRequestBodyObject(request *http.Request, requestBodyType reflect.Type) (interface{}, error) {
+// Returned by `toRequestBodyObject()` when the body doesn't match the declared Content-Length
+var errContentLengthMismatch = errors.New("request body length does not match the declared Content-Length")
+
+// The body may carry personal data or credentials, it's only logged if `logBody` is true (see `Dispatcher.LogRequestBodies`)
+func toRequestBodyObject(request *http.Request, requestBodyType reflect.Type, logBody bool) (interface{}, error) {
 	bodyBytes, err := ioutil.ReadAll(request.Body)
-	if err != nil {
+	if err == io.ErrUnexpectedEOF {
+		// The server's body reader stops short of the declared Content-Length
+		return nil, fmt.Errorf("%w: declared %d bytes but received less", errContentLengthMismatch, request.ContentLength)
+	} else if err != nil {
 		return nil, err
 	}
-	log.Debug("[toRequestBodyObject] bodyBytes => %s", bodyBytes)
+
+	// ContentLength is -1 when unknown (ex: chunked transfer encoding)
+	if request.ContentLength >= 0 && int64(len(bodyBytes)) != request.ContentLength {
+		return nil, fmt.Errorf("%w: declared %d bytes but received %d", errContentLengthMismatch, request.ContentLength, len(bodyBytes))
+	}
+	if logBody {
+		log.Debug("[toRequestBodyObject] bodyBytes => %s", bodyBytes)
+	}
 
 	objectToFill := reflect.New(requestBodyType).Interface()
 	if unmarshalErr := unmarshal(request.Header.Get("Content-Type"), bodyBytes, objectToFill); unmarshalErr != nil {
@@ -506,6 +1438,99 @@ type Dispatcher struct {
 	routes Routes
 	preFilters []FilterFunc
 	postFilters []FilterFunc
+
+	// Builds the response sent when a handler or a filter panics, `defaultPanicHandler` is used if nil
+	PanicHandler func(h *Http, recovered interface{}) HttpResponse
+
+	// Requests with more header fields, or more header bytes (names + values), are rejected with
+	// `431 Request Header Fields Too Large`. Zero disables the check.
+	MaxHeaderCount int
+	MaxHeaderSize int
+
+	// Regex matching a path variable's value, defaults to `[a-zA-Z0-9_-]+`.
+	// Routes are compiled by `NewDispatcher()`, `Compile()` must be called after changing it.
+	PathVariablePattern string
+	regexPaths map[CustomHandler]*regexp.Regexp
+
+	// HttpMethod => Path => CustomHandler, for routes without path variables
+	staticRoutes map[string]map[string]CustomHandler
+
+	// HttpMethod => Routes bound to a host, see `Routes.Host()`
+	hostRoutes map[string][]CustomHandler
+
+	// Logs one JSON object per request (method, path, status, duration, request ID) at the info level
+	StructuredLog bool
+
+	// Collapses repeated slashes and resolves `.`/`..` elements before routing (ex: `/users//42` => `/users/42`),
+	// `CleanPathRedirect` answers `301 Moved Permanently` to the cleaned path instead
+	CleanPath bool
+	CleanPathRedirect bool
+
+	// Answers `301 Moved Permanently` to the path with or without its trailing slash (ex: `/users/` => `/users`)
+	// when only that other form is routed for the request's method, `308 Permanent Redirect` for the methods other than
+	// GET and HEAD so that clients replay the method and the body
+	TrailingSlashRedirect bool
+
+	// Strict-accept mode: content types produced by routes which don't use `WithProduces()`,
+	// requests whose `Accept` header allows none of them are rejected with `406 Not Acceptable`. Nil disables the check.
+	Produces []string
+
+	// Rewrites marshaled bodies of `ResponseWriter` responses (ex: signing, wrapping), the Content-Length is computed
+	// from the returned bytes. Nil disables the transformation.
+	ResponseTransform func(contentType string, body []byte) []byte
+
+	// Deadline of the handlers, the request's context is canceled when it expires and `503 Service Unavailable` is sent.
+	// The handler's response is buffered so that it can be discarded. `WithTimeout()` overrides it per route, zero disables it.
+	HandlerTimeout time.Duration
+
+	// Sent when a handler returns a nil `HttpResponse` without writing to `Http.Response` itself, defaults to `204 No Content`
+	DefaultResponse HttpResponse
+
+	// Requests with a larger body are rejected with `413 Request Entity Too Large`, also applies to `Http.RawBody()`
+	// (the read fails). `WithRawBody()` routes are exempt. Zero disables the limit.
+	MaxBodySize int64
+
+	// Bytes of a `multipart/form-data` body kept in memory by `Http.FormFile()` and `Http.FormValue()`, the rest of
+	// the files is stored on disk. Zero defaults to 32 MB. The whole body is still bounded by `MaxBodySize`.
+	MultipartMemory int64
+
+	// Compresses JSON, XML and text bodies with gzip when the request's `Accept-Encoding` allows it
+	Gzip bool
+
+	// Rejects GET and HEAD requests carrying a body with `400 Bad Request`, to catch client bugs early
+	RejectGetBody bool
+
+	// Deadline of each write of the streaming responses (`StreamResponse()`, `EventStreamResponse()`), so that a stalled
+	// client fails the stream instead of blocking the handler forever. Zero disables it.
+	StreamWriteTimeout time.Duration
+
+	// Sends the handler's duration in a `Server-Timing: app;dur=<milliseconds>` header, measured until the header is written
+	ServerTiming bool
+
+	// Wrap the responses returned by the handlers before they're written (ex: `WithHeaders()`), in order: the last one
+	// is the outermost. Unlike the post-filters, they can still change the headers and the status.
+	ResponseMiddleware []func(HttpResponse) HttpResponse
+
+	// Answers the OPTIONS requests with `204 No Content` and an `Allow` header listing the methods of the routes matching
+	// the path. A registered OPTIONS route takes precedence.
+	AutoOptions bool
+
+	// Logs the request bodies at the debug level, for debugging only: they may carry personal data or credentials
+	LogRequestBodies bool
+
+	// Records the latency of each matched route, see `RouteStats()`
+	CollectRouteStats bool
+	routeStatsMutex sync.Mutex
+	routeStats map[string]*RouteStat
+
+	// Paths still served in maintenance mode (ex: `/health`), see `SetMaintenanceMode()`
+	MaintenanceExemptPaths []string
+
+	// Accessed atomically, see `InFlight()`, `Drain()` and `SetMaintenanceMode()`
+	inFlight int64
+	draining int32
+	maintenance int32
+	maintenanceRetryAfter int64
 }
 
 func NewDispatcher(routes Routes, filters *Filters) *Dispatcher {
@@ -516,6 +1541,9 @@ func NewDispatcher(routes Routes, filters *Filters) *Dispatcher {
 	dispatcher := new(Dispatcher)
 	dispatcher.routes = routes
 
+	// The default pattern always compiles
+	dispatcher.Compile()
+
 	if filters == nil {
 		return dispatcher
 	}
@@ -531,9 +1559,90 @@ func NewDispatcher(routes Routes, filters *Filters) *Dispatcher {
 	return dispatcher
 }
 
-func (dispatcher *Dispatcher) getHandler(httpMethod string, calledPath string) (CustomHandler, error) {
+// Indexes the static routes and compiles the routes' regexes against `PathVariablePattern`. Already done by
+// `NewDispatcher()`, it must be called again after changing `PathVariablePattern` or the routes, before serving requests.
+// Nothing is changed if `PathVariablePattern` isn't a valid regex.
+func (dispatcher *Dispatcher) Compile() error {
+	regexPaths := make(map[CustomHandler]*regexp.Regexp)
+	if dispatcher.PathVariablePattern != "" && dispatcher.PathVariablePattern != defaultPathVariablePattern {
+		if _, err := regexp.Compile(dispatcher.PathVariablePattern); err != nil {
+			return fmt.Errorf("[Dispatcher#Compile] Invalid PathVariablePattern '%s' => %w", dispatcher.PathVariablePattern, err)
+		}
+
+		// Grouping in case of alternations. Ex: `[0-9]+|[a-f]+`
+		pathVariablePattern := "(?:" + dispatcher.PathVariablePattern + ")"
+		for _, handlers := range dispatcher.routes {
+			for _, handler := range handlers {
+				if handler.GetPath() != "" {
+					regexPaths[handler] = toRegexPath(handler.GetPath(), pathVariablePattern)
+				}
+			}
+		}
+	}
+	dispatcher.regexPaths = regexPaths
+
+	// Routes without path variables are matched by direct string comparison. The first registered route matching a
+	// path wins, so a static route shadowed by an earlier route with path variables (ex: `/users/me` registered after
+	// `/users/{id}`) isn't indexed and is matched in registration order.
+	dispatcher.staticRoutes = make(map[string]map[string]CustomHandler)
+	dispatcher.hostRoutes = make(map[string][]CustomHandler)
+	for httpMethod, handlers := range dispatcher.routes {
+		dispatcher.staticRoutes[httpMethod] = make(map[string]CustomHandler)
+		var dynamicHandlers []CustomHandler
+		for _, handler := range handlers {
+			if handler.GetHost() != "" {
+				dispatcher.hostRoutes[httpMethod] = append(dispatcher.hostRoutes[httpMethod], handler)
+				continue
+			}
+
+			path := handler.GetPath()
+			if path == "" || handler.GetPathVariableNames() != nil {
+				dynamicHandlers = append(dynamicHandlers, handler)
+				continue
+			}
+
+			if _, exists := dispatcher.staticRoutes[httpMethod][path]; !exists && !dispatcher.isShadowed(path, dynamicHandlers) {
+				dispatcher.staticRoutes[httpMethod][path] = handler
+			}
+		}
+	}
+
+	return nil
+}
+
+func (dispatcher *Dispatcher) isShadowed(path string, earlierHandlers []CustomHandler) bool {
+	for _, handler := range earlierHandlers {
+		if dispatcher.regexPath(handler).MatchString(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (dispatcher *Dispatcher) regexPath(handler CustomHandler) *regexp.Regexp {
+	if regexPath, exists := dispatcher.regexPaths[handler]; exists {
+		return regexPath
+	}
+
+	return handler.GetRegexPath()
+}
+
+// Hot path: a static route is found without any allocation.
+// Routes bound to the request's host win over the other routes, see `Routes.Host()`.
+func (dispatcher *Dispatcher) getHandler(httpMethod string, host string, calledPath string) (CustomHandler, error) {
+	for _, handler := range dispatcher.hostRoutes[httpMethod] {
+		if matchHost(handler.GetHost(), host) && dispatcher.regexPath(handler).MatchString(calledPath) {
+			return handler, nil
+		}
+	}
+
+	if handler, exists := dispatcher.staticRoutes[httpMethod][calledPath]; exists {
+		return handler, nil
+	}
+
 	for _, handler := range dispatcher.routes[httpMethod] {
-		if handler.GetRegexPath().MatchString(calledPath) {
+		if handler.GetHost() == "" && dispatcher.regexPath(handler).MatchString(calledPath) {
 			return handler, nil
 		}
 	}
@@ -542,6 +1651,231 @@ func (dispatcher *Dispatcher) getHandler(httpMethod string, calledPath string) (
 	return nil, errors.New(fmt.Sprintf("[Dispatcher#getHandler] Route does NOT exists => Method: '%s' | Path: '%s'", httpMethod, calledPath))
 }
 
+// Methods having a route matching the path, sorted. Each method is listed once, even if several of its routes match.
+func (dispatcher *Dispatcher) allowedMethods(host string, calledPath string) []string {
+	var allowedMethods []string
+	allowed := make(map[string]bool)
+	for httpMethod, handlers := range dispatcher.routes {
+		if _, exists := dispatcher.staticRoutes[httpMethod][calledPath]; exists {
+			allowedMethods = append(allowedMethods, httpMethod)
+			allowed[httpMethod] = true
+			continue
+		}
+
+		for _, handler := range handlers {
+			if (handler.GetHost() == "" || matchHost(handler.GetHost(), host)) && dispatcher.regexPath(handler).MatchString(calledPath) {
+				allowedMethods = append(allowedMethods, httpMethod)
+				allowed[httpMethod] = true
+				break
+			}
+		}
+	}
+
+	// HEAD is answered by the GET routes, see `ServeHTTP()`
+	if allowed[http.MethodGet] && !allowed[http.MethodHead] {
+		allowedMethods = append(allowedMethods, http.MethodHead)
+	}
+
+	// OPTIONS is answered by the dispatcher, see `AutoOptions`
+	if dispatcher.AutoOptions && allowedMethods != nil && !allowed[http.MethodOptions] {
+		allowedMethods = append(allowedMethods, http.MethodOptions)
+	}
+
+	sort.Strings(allowedMethods)
+	return allowedMethods
+}
+
+// Request URI with the trailing slash of the path toggled, if the request's method is routed for it
+func (dispatcher *Dispatcher) trailingSlashLocation(request *http.Request) (string, bool) {
+	if request.URL.Path == "/" {
+		return "", false
+	}
+
+	alternateURL := *request.URL
+	alternateURL.RawPath = ""
+	if strings.HasSuffix(request.URL.Path, "/") {
+		alternateURL.Path = strings.TrimSuffix(request.URL.Path, "/")
+	} else {
+		alternateURL.Path = request.URL.Path + "/"
+	}
+
+	for _, httpMethod := range dispatcher.allowedMethods(request.Host, alternateURL.Path) {
+		if httpMethod == request.Method {
+			return alternateURL.RequestURI(), true
+		}
+	}
+
+	return "", false
+}
+
+func (dispatcher *Dispatcher) checkHeaderLimits(header http.Header) error {
+	if dispatcher.MaxHeaderCount <= 0 && dispatcher.MaxHeaderSize <= 0 {
+		return nil
+	}
+
+	count, size := 0, 0
+	for name, values := range header {
+		count += len(values)
+		for _, value := range values {
+			size += len(name) + len(value)
+		}
+	}
+
+	if dispatcher.MaxHeaderCount > 0 && count > dispatcher.MaxHeaderCount {
+		return fmt.Errorf("[Dispatcher#checkHeaderLimits] %d header fields exceed the limit of %d", count, dispatcher.MaxHeaderCount)
+	}
+
+	if dispatcher.MaxHeaderSize > 0 && size > dispatcher.MaxHeaderSize {
+		return fmt.Errorf("[Dispatcher#checkHeaderLimits] %d header bytes exceed the limit of %d", size, dispatcher.MaxHeaderSize)
+	}
+
+	return nil
+}
+
+// Line logged when `StructuredLog` is enabled
+type requestLog struct {
+	Method string `json:"method"`
+	Path string `json:"path"`
+	Status int `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+func (dispatcher *Dispatcher) logRequest(response *responseRecorder, request *http.Request, start time.Time) {
+	line, err := json.Marshal(&requestLog{
+		Method: request.Method,
+		Path: request.URL.Path,
+		Status: response.status(),
+		DurationMs: float64(time.Since(start)) / float64(time.Millisecond),
+		RequestID: RequestID(request)})
+
+	if err != nil {
+		log.Debug("[Dispatcher#logRequest] json.Marshal => %s", err.Error())
+		return
+	}
+
+	log.Info("%s", line)
+}
+
+// Like `path.Clean()`, but keeps the trailing slash
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+
+	cleanedPath := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleanedPath != "/" {
+		cleanedPath += "/"
+	}
+
+	return cleanedPath
+}
+
+type contextKey int
+
+const (
+	requestIDContextKey contextKey = iota
+	dispatcherContextKey
+	defaultContentTypeContextKey
+)
+
+// Returns a copy of the request carrying the correlation identifier, for middlewares generating request IDs
+func WithRequestID(request *http.Request, requestID string) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), requestIDContextKey, requestID))
+}
+
+// Correlation identifier of the request: set by `WithRequestID()`, otherwise sent by the client or a proxy as `X-Request-ID`
+func RequestID(request *http.Request) string {
+	if requestID, ok := request.Context().Value(requestIDContextKey).(string); ok {
+		return requestID
+	}
+
+	return request.Header.Get("X-Request-ID")
+}
+
+func defaultPanicHandler(h *Http, recovered interface{}) HttpResponse {
+	return JsonErrorResponse(http.StatusInternalServerError, h.Request, http.StatusText(http.StatusInternalServerError))
+}
+
+// Returns false if the request body couldn't be read, in which case the post-filters are skipped
+func (dispatcher *Dispatcher) invokeHandler(response http.ResponseWriter, request *http.Request, handler CustomHandler, calledPath string) bool {
+	var pathVariableValues map[string]string
+	if pathVariableNames := handler.GetPathVariableNames(); pathVariableNames != nil {
+		pathVariableValues = extractPathVariableValues(dispatcher.regexPath(handler), calledPath, pathVariableNames)
+	}
+	h := &Http{Response: response, Request: request, PathVariables: pathVariableValues, multipartMemory: dispatcher.MultipartMemory}
+	defer dispatcher.recoverHandler(h)
+	if !handler.HasRequestBody() {
+		inputs := inputsWithoutRequestBody(h)
+		handler.WriteHttpResponse(response, request, inputs)
+	} else {
+		if requestBody, err := toRequestBodyObject(request, handler.GetRequestBodyType(), dispatcher.LogRequestBodies); err != nil {
+			log.Debug("[Dispatcher#invokeHandler][toRequestBodyObject] %s", err.Error())
+			// Otherwise a length mismatch, a read or an unmarshalling error
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				JsonErrorResponse(http.StatusRequestEntityTooLarge, request, fmt.Sprintf("Request body must not exceed %d bytes", maxBytesError.Limit)).write(response, request)
+			} else {
+				JsonErrorResponse(http.StatusBadRequest, request, err.Error()).write(response, request)
+			}
+			return false
+		} else {
+			inputs := inputsWithRequestBody(h, requestBody)
+			handler.WriteHttpResponse(response, request, inputs)
+		}
+	}
+
+	// The handler returned a nil `HttpResponse` without writing to `Http.Response` itself
+	if recorder, ok := response.(statusRecorder); ok && !recorder.written() {
+		defaultResponse := dispatcher.DefaultResponse
+		if defaultResponse == nil {
+			defaultResponse = NoContentResponse()
+		}
+		defaultResponse.write(response, request)
+	}
+
+	return true
+}
+
+// Must be deferred: recovers from a handler or filter panic and writes the `PanicHandler` response
+func (dispatcher *Dispatcher) recoverHandler(h *Http) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	} else if recovered == http.ErrAbortHandler {
+		// Intentional abort (ex: `Http.WriteError()`), the server closes the connection
+		panic(recovered)
+	} else if pathVariableError, ok := recovered.(*PathVariableError); ok {
+		// `Http.MustPathInt()`, a client error rather than a bug
+		log.Debug("[Dispatcher#recoverHandler] Method: '%s' | Path: '%s' | %s", h.Request.Method, h.Request.URL.Path, pathVariableError.Error())
+		if recorder, ok := h.Response.(statusRecorder); !ok || !recorder.written() {
+			errorResponse(h.Request, pathVariableError).write(h.Response, h.Request)
+		}
+		return
+	}
+
+	log.Debug("[Dispatcher#recoverHandler] Method: '%s' | Path: '%s' | RequestId: '%s' | Panic => %v\n%s",
+		h.Request.Method,
+		h.Request.URL.Path,
+		RequestID(h.Request),
+		recovered,
+		debug.Stack())
+
+	// Ex: a post-filter panicking once the handler's response is sent
+	if recorder, ok := h.Response.(statusRecorder); ok && recorder.written() {
+		return
+	}
+
+	panicHandler := dispatcher.PanicHandler
+	if panicHandler == nil {
+		panicHandler = defaultPanicHandler
+	}
+
+	if panicResponse := panicHandler(h, recovered); panicResponse != nil {
+		panicResponse.write(h.Response, h.Request)
+	}
+}
+
 func executeFilters(response http.ResponseWriter, request *http.Request, filters []FilterFunc) bool {
 	for _, filter := range filters {
 		if !filter(response, request) {
@@ -552,37 +1886,181 @@ func executeFilters(response http.ResponseWriter, request *http.Request, filters
 	return true
 }
 
-func (dispatcher *Dispatcher) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+func (dispatcher *Dispatcher) ServeHTTP(httpResponse http.ResponseWriter, request *http.Request) {
+	atomic.AddInt64(&dispatcher.inFlight, 1)
+	defer atomic.AddInt64(&dispatcher.inFlight, -1)
+
+	var writer http.ResponseWriter = httpResponse
+	// HEAD responses keep the Content-Length of the uncompressed body, there's no body to compress
+	if dispatcher.Gzip && request.Method != http.MethodHead && acceptsEncoding(request.Header.Get("Accept-Encoding"), "gzip") {
+		gzipWriter := &gzipResponseWriter{ResponseWriter: httpResponse}
+		defer gzipWriter.close()
+		writer = gzipWriter
+	}
+
+	response := &responseRecorder{ResponseWriter: writer}
+	if dispatcher.ResponseTransform != nil || dispatcher.StreamWriteTimeout > 0 || dispatcher.ResponseMiddleware != nil {
+		// Responses only see the request, the dispatcher's settings are reached through its context
+		request = request.WithContext(context.WithValue(request.Context(), dispatcherContextKey, dispatcher))
+	}
+	if dispatcher.StructuredLog {
+		defer dispatcher.logRequest(response, request, time.Now())
+	}
+
+	if dispatcher.MaintenanceMode() && !dispatcher.isMaintenanceExempt(request.URL.Path) {
+		dispatcher.maintenanceResponse(request).write(response, request)
+		return
+	}
+
+	if err := dispatcher.checkHeaderLimits(request.Header); err != nil {
+		log.Debug("%s", err.Error())
+		JsonErrorResponse(http.StatusRequestHeaderFieldsTooLarge, request, http.StatusText(http.StatusRequestHeaderFieldsTooLarge)).write(response, request)
+		return
+	}
+
+	// A length of -1 means unknown (ex: chunked), so a body is present
+	if dispatcher.RejectGetBody && (request.Method == http.MethodGet || request.Method == http.MethodHead) && request.ContentLength != 0 {
+		log.Debug("[Dispatcher#ServeHTTP] Body rejected => Method: '%s' | Path: '%s' | Content-Length: %d", request.Method, request.URL.Path, request.ContentLength)
+		JsonErrorResponse(http.StatusBadRequest, request, "No request body expected").write(response, request)
+		return
+	}
+
+	if dispatcher.CleanPath {
+		if cleanedPath := cleanPath(request.URL.Path); cleanedPath != request.URL.Path {
+			cleanedURL := *request.URL
+			cleanedURL.Path = cleanedPath
+			cleanedURL.RawPath = ""
+
+			if dispatcher.CleanPathRedirect {
+				response.Header().Set("Location", cleanedURL.RequestURI())
+				response.WriteHeader(http.StatusMovedPermanently)
+				return
+			}
+
+			// Shallow copy, the caller's request must not be modified
+			request = request.WithContext(request.Context())
+			request.URL = &cleanedURL
+		}
+	}
+
+	// Routes are matched against the path component only: the query string and, for absolute-form
+	// request URIs (ex: `GET http://host/path?q=1`), the scheme and host are never part of it
 	calledPath := request.URL.Path
-	handler, err := dispatcher.getHandler(request.Method, calledPath)
+	handler, err := dispatcher.getHandler(request.Method, request.Host, calledPath)
+	if err != nil && request.Method == http.MethodHead {
+		// Answered by the GET route, without body
+		if getHandler, getErr := dispatcher.getHandler(http.MethodGet, request.Host, calledPath); getErr == nil {
+			handler, err = getHandler, nil
+			response.discardBody = true
+		}
+	}
 	if err != nil {
 		// Printing debug
-		log.Debug(err.Error())
-		response.WriteHeader(http.StatusNotFound)
+		log.Debug("%s", err.Error())
+
+		// The path exists under other methods
+		if allowedMethods := dispatcher.allowedMethods(request.Host, calledPath); allowedMethods != nil {
+			response.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+
+			// CORS preflights go through the pre-filters (ex: `CORSFilter()`), which can answer them
+			if request.Method == http.MethodOptions {
+				defer dispatcher.recoverHandler(&Http{Response: response, Request: request})
+				if !executeFilters(response, request, dispatcher.preFilters) {
+					return
+				}
+			}
+
+			if request.Method == http.MethodOptions && dispatcher.AutoOptions {
+				response.WriteHeader(http.StatusNoContent)
+				return
+			}
+			negotiatedErrorResponse(http.StatusMethodNotAllowed, request).write(response, request)
+			return
+		}
+
+		if dispatcher.TrailingSlashRedirect {
+			if location, found := dispatcher.trailingSlashLocation(request); found {
+				response.Header().Set("Location", location)
+				if request.Method == http.MethodGet || request.Method == http.MethodHead {
+					response.WriteHeader(http.StatusMovedPermanently)
+				} else {
+					// A 301 would be replayed as a GET, without the body
+					response.WriteHeader(http.StatusPermanentRedirect)
+				}
+				return
+			}
+		}
+
+		negotiatedErrorResponse(http.StatusNotFound, request).write(response, request)
 		return
 	}
 
 	log.Debug("[Dispatcher#ServeHTTP] => Method: '%s' | Path: '%s'", request.Method, calledPath)
+	if dispatcher.CollectRouteStats {
+		defer dispatcher.recordRouteStat(request.Method, handler, time.Now())
+	}
+
+	// Content negotiation, a ContentLength of 0 means there's no body to check
+	if consumes := handler.GetConsumes(); consumes != nil && request.ContentLength != 0 && !isConsumed(request.Header.Get("Content-Type"), consumes) {
+		JsonErrorResponse(http.StatusUnsupportedMediaType, request, fmt.Sprintf("Content-Type must be one of %v", consumes)).write(response, request)
+		return
+	}
+
+	produces := handler.GetProduces()
+	if produces == nil {
+		produces = dispatcher.Produces
+	}
+	if produces != nil && negotiateContentType(request.Header.Get("Accept"), produces) == "" {
+		JsonErrorResponse(http.StatusNotAcceptable, request, fmt.Sprintf("Accept must allow one of %v", produces)).write(response, request)
+		return
+	}
+
+	// Streamed bodies are bounded by the handler itself
+	if dispatcher.MaxBodySize > 0 && !handler.HasRawBody() {
+		if request.ContentLength > dispatcher.MaxBodySize {
+			JsonErrorResponse(http.StatusRequestEntityTooLarge, request, fmt.Sprintf("Request body must not exceed %d bytes", dispatcher.MaxBodySize)).write(response, request)
+			return
+		}
+
+		// Unknown lengths (ex: chunked) are checked while reading. Shallow copy, the caller's request must not be modified.
+		limitedRequest := request.WithContext(request.Context())
+		limitedRequest.Body = http.MaxBytesReader(response, request.Body, dispatcher.MaxBodySize)
+		request = limitedRequest
+	}
+
+	// Handler panics are recovered by `invokeHandler()`, with the path variables
+	defer dispatcher.recoverHandler(&Http{Response: response, Request: request})
 
 	// Executing pre-filters
 	if !executeFilters(response, request, dispatcher.preFilters) {
 		return
 	}
 
+	// Executing route filters
+	if !executeFilters(response, request, handler.GetFilters()) {
+		return
+	}
+
+	// The client is gone (ex: disconnected during the filters), nobody would read the response
+	if err := request.Context().Err(); err != nil {
+		log.Debug("[Dispatcher#ServeHTTP] Method: '%s' | Path: '%s' | Handler skipped => %s", request.Method, calledPath, err.Error())
+		return
+	}
+
 	// Executing handler
-	pathVariableValues := extractPathVariableValues(calledPath, handler.GetPathVariableNames())
-	http := &Http{Response: response, Request: request, PathVariables: pathVariableValues}
-	if !handler.HasRequestBody() {
-		inputs := inputsWithoutRequestBody(http)
-		handler.WriteHttpResponse(response, inputs)
-	} else {
-		if requestBody, err := toRequestBodyObject(request, handler.GetRequestBodyType()); err != nil {
-			log.Debug("[Dispatcher#ServeHTTP][toRequestBodyObject] %s", err.Error())
+	if dispatcher.ServerTiming {
+		response.handlerStart = time.Now()
+	}
+	timeout := handler.GetTimeout()
+	if timeout == 0 {
+		timeout = dispatcher.HandlerTimeout
+	}
+	if timeout > 0 {
+		if !dispatcher.invokeHandlerWithTimeout(response, request, handler, calledPath, timeout) {
 			return
-		} else {
-			inputs := inputsWithRequestBody(http, requestBody)
-			handler.WriteHttpResponse(response, inputs)
 		}
+	} else if !dispatcher.invokeHandler(response, request, handler, calledPath) {
+		return
 	}
 
 	// Executing post-filters
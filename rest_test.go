@@ -2,7 +2,18 @@ package rest
 
 import (
 	"testing"
+	"errors"
+	"context"
 	"fmt"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing/iotest"
+	"time"
 )
 
 func TestIsHttpMethodBodyable_when_parameterIsEmptyString(t *testing.T) {
@@ -44,6 +55,40 @@ func TestIsHttpMethodBodyable_when_parameterIsPost(t *testing.T) {
 	}
 }
 
+func TestIsHttpMethodBodyable_when_parameterIsBodyless(t *testing.T) {
+	for _, httpMethod := range []string{http.MethodGet, http.MethodHead, http.MethodOptions} {
+		// WHEN
+		actual := isHttpMethodBodyable(httpMethod)
+
+		// THEN
+		if actual == true {
+			t.Errorf("Method '%s' => actual: '%t', expected: '%t'.", httpMethod, actual, false)
+		}
+	}
+}
+
+func TestRoutesAddRouteE_when_customMethodWithRequestBody(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return TextResponse(http.StatusOK, strconv.Itoa(body.A))
+	}
+
+	// WHEN
+	err := routes.AddRouteE("PROPFIND", "/a", handler)
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	recorder := httptest.NewRecorder()
+	NewDispatcher(routes, nil).ServeHTTP(recorder, httptest.NewRequest("PROPFIND", "/a", strings.NewReader(`{"a":42}`)))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "42" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "42")
+	}
+}
+
 func TestIsValidPath_when_error_parameterIsEmptyString(t *testing.T) {
 	// GIVEN
 	var emptyString string
@@ -112,6 +157,19 @@ func TestIsValidPath_when_nominal_complexValue(t *testing.T) {
 	}
 }
 
+func TestIsValidPath_when_error_duplicatedPathVariable(t *testing.T) {
+	// GIVEN
+	var path string = "/{aa0}/{aa0}"
+
+	// WHEN
+	actual, err := isValidPath(path)
+
+	// THEN
+	if actual == true || err == nil {
+		t.Errorf("Actual: '%t', expected: '%t' with an error", actual, false)
+	}
+}
+
 func TestExtractPathVariableNames_when_nominal(t *testing.T) {
 	// GIVEN
 	var path string = "/a/{mo-ck1}/bbb/{m-o-ck2}/a-b-c1/{mock3}"
@@ -164,7 +222,7 @@ func TestExtractPathVariableValues_when_nominal(t *testing.T) {
 	}
 
 	// WHEN
-	actual := extractPathVariableValues(path, pathVariables)
+	actual := extractPathVariableValues(nil, path, pathVariables)
 
 	// THEN
 	if len(actual) != 3 {
@@ -184,6 +242,178 @@ func TestExtractPathVariableValues_when_nominal(t *testing.T) {
 	}
 }
 
+func TestIsValidPath_when_nominal_catchAll(t *testing.T) {
+	// GIVEN
+	var path string = "/files/{p...}"
+
+	// WHEN
+	actual, err := isValidPath(path)
+
+	// THEN
+	if actual == false {
+		t.Errorf("Actual: '%t', expected: '%t', error: '%v'", actual, true, err)
+	}
+}
+
+func TestIsValidPath_when_error_catchAllNotLast(t *testing.T) {
+	// GIVEN
+	var path string = "/files/{p...}/a"
+
+	// WHEN
+	actual, err := isValidPath(path)
+
+	// THEN
+	if actual == true {
+		t.Errorf("Actual: '%t', expected: '%t', error: '%v'", actual, false, err)
+	}
+}
+
+func TestIsValidPath_when_error_unknownType(t *testing.T) {
+	// GIVEN
+	var path string = "/users/{id:float}"
+
+	// WHEN
+	actual, err := isValidPath(path)
+
+	// THEN
+	if actual == true || err == nil {
+		t.Errorf("Actual: '%t', expected: '%t' with an error", actual, false)
+	}
+}
+
+func TestExtractPathVariableNames_when_typed(t *testing.T) {
+	// GIVEN
+	var path string = "/users/{id:int}/x/{u:uuid}"
+
+	// WHEN
+	v := extractPathVariableNames(path)
+
+	// THEN
+	expected := []PathVariable{
+		PathVariable{pathIndex: 1, variableName: "id", variableType: "int"},
+		PathVariable{pathIndex: 3, variableName: "u", variableType: "uuid"},
+	}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("Actual: '%+v', expected: '%+v'", v, expected)
+	}
+}
+
+func TestDispatcher_when_typedPathVariables(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, h.PathVariables["id"] + h.PathVariables["u"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id:int}", handler).GET("/x/{u:uuid}", handler), nil)
+
+	for calledPath, expected := range map[string]int{
+		"/users/42": http.StatusOK,
+		"/users/abc": http.StatusNotFound,
+		"/users/4a": http.StatusNotFound,
+		"/x/123e4567-e89b-12d3-a456-426614174000": http.StatusOK,
+		"/x/123e4567": http.StatusNotFound} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, calledPath, nil))
+
+		// THEN
+		if recorder.Code != expected {
+			t.Errorf("Actual: '%v', expected: '%v' (path: '%s')", recorder.Code, expected, calledPath)
+		}
+	}
+}
+
+func TestExtractPathVariableValues_when_catchAll(t *testing.T) {
+	// GIVEN
+	pathVariables := extractPathVariableNames("/files/{p...}")
+
+	// WHEN
+	actual := extractPathVariableValues(nil, "/files/a/b/c.txt", pathVariables)
+
+	// THEN
+	if actual["p"] != "a/b/c.txt" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual["p"], "a/b/c.txt")
+	}
+}
+
+func TestDispatcher_when_catchAll(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, h.PathVariables["p"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/files/{p...}", handler), nil)
+
+	for calledPath, expected := range map[string]int{"/files/a/b/c.txt": http.StatusOK, "/files/a.txt": http.StatusOK, "/files/": http.StatusNotFound, "/files": http.StatusNotFound} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, calledPath, nil))
+
+		// THEN
+		if recorder.Code != expected {
+			t.Errorf("Actual: '%v', expected: '%v' (path: '%s')", recorder.Code, expected, calledPath)
+		} else if expected == http.StatusOK && "/files/" + recorder.Body.String() != calledPath {
+			t.Errorf("Actual: '%v', expected: '%v'", "/files/" + recorder.Body.String(), calledPath)
+		}
+	}
+}
+
+func TestDispatcher_when_pathVariablePatternMatchesSlash(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, h.PathVariables["file-name"] + "|" + h.PathVariables["v"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/files/{file-name}/meta/{v:int}", handler), nil)
+	dispatcher.PathVariablePattern = ".+"
+	if err := dispatcher.Compile(); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/files/a/b/meta/2", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "a/b|2" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "a/b|2")
+	}
+}
+
+func TestDispatcher_when_pathVariablePatternWithoutSlash(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, h.PathVariables["name"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/files/{name}/meta", handler), nil)
+	dispatcher.PathVariablePattern = `[a-z.]+`
+	if err := dispatcher.Compile(); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	for calledPath, expected := range map[string]string{"/files/a.txt/meta": "a.txt", "/files/a/b/meta": ""} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, calledPath, nil))
+
+		// THEN
+		if recorder.Body.String() != expected && !(expected == "" && recorder.Code == http.StatusNotFound) {
+			t.Errorf("Path '%s' => actual: '%d %s', expected: '%s'", calledPath, recorder.Code, recorder.Body.String(), expected)
+		}
+	}
+}
+
+func TestToRegexPath_when_customPattern(t *testing.T) {
+	// WHEN
+	regex := toRegexPath("/a/{mo-ck1}/{n:int}/{p...}", "(?:.+)")
+
+	// THEN
+	expected := "^/a/(?P<mo_ck1>(?:.+))/(?P<n>[0-9]+)/(?P<p>(.+))$"
+	if regex.String() != expected {
+		t.Errorf("Actual: '%s', expected: '%s'", regex.String(), expected)
+	}
+}
+
 func TestExtractPathVariableValues_when_empty(t *testing.T) {
 	// GIVEN
 	var path string = "/a/111111/bbb/222222/a-b-c1/333333"
@@ -191,7 +421,7 @@ func TestExtractPathVariableValues_when_empty(t *testing.T) {
 	var pathVariables []PathVariable = nil
 
 	// WHEN
-	actual := extractPathVariableValues(path, pathVariables)
+	actual := extractPathVariableValues(nil, path, pathVariables)
 
 	// THEN
 	if actual == nil {
@@ -208,12 +438,2640 @@ func TestToRegexPath_when_nominal(t *testing.T) {
 	var path string = "/a/{mo-ck1}/bbb/{m-o-ck2}/a-b-c1/{mock3}"
 
 	// WHEN
-	regex := toRegexPath(path)
+	regex := toRegexPath(path, defaultPathVariablePattern)
 
 	// THEN
 	s := "[a-zA-Z0-9_-]+"
-	expected := fmt.Sprintf("/a/%s/bbb/%s/a-b-c1/%s", s, s, s)
+	expected := fmt.Sprintf("^/a/%s/bbb/%s/a-b-c1/%s$", s, s, s)
 	if regex.String() != expected {
 		t.Errorf("Actual: '%s', expected: '%s'", regex.String(), expected)
 	}
-}
\ No newline at end of file
+}
+
+func TestWithLastModified_when_notModifiedSince(t *testing.T) {
+	// GIVEN
+	lastModified := time.Date(2018, time.September, 5, 10, 0, 0, 0, time.UTC)
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("If-Modified-Since", lastModified.Format(http.TimeFormat))
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	WithLastModified(JsonResponse(200, map[string]int{"a": 1}), lastModified).write(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusNotModified {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNotModified)
+	}
+
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "")
+	}
+}
+
+func TestWithLastModified_when_modifiedSince(t *testing.T) {
+	// GIVEN
+	lastModified := time.Date(2018, time.September, 5, 10, 0, 0, 0, time.UTC)
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("If-Modified-Since", lastModified.Add(-time.Hour).Format(http.TimeFormat))
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	WithLastModified(JsonResponse(200, map[string]int{"a": 1}), lastModified).write(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusOK)
+	}
+
+	if recorder.Body.String() != `{"a":1}` {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), `{"a":1}`)
+	}
+
+	if actual := recorder.Header().Get("Last-Modified"); actual != lastModified.Format(http.TimeFormat) {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, lastModified.Format(http.TimeFormat))
+	}
+}
+
+// Hides the `http.Flusher` implementation of the embedded writer
+type nonFlushingWriter struct {
+	http.ResponseWriter
+}
+
+func TestFlush_when_writerIsNotFlusher(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+	writer := &nonFlushingWriter{ResponseWriter: recorder}
+
+	// WHEN
+	for _, chunk := range []string{"a", "b", "c"} {
+		writer.Write([]byte(chunk))
+		flush(writer)
+	}
+
+	// THEN
+	if recorder.Flushed {
+		t.Errorf("Actual: '%t', expected: '%t'", recorder.Flushed, false)
+	}
+
+	if recorder.Body.String() != "abc" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "abc")
+	}
+}
+
+func TestFlush_when_writerIsFlusher(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	flush(recorder)
+
+	// THEN
+	if !recorder.Flushed {
+		t.Errorf("Actual: '%t', expected: '%t'", recorder.Flushed, true)
+	}
+}
+
+type mockRequestBody struct {
+	A int `json:"a"`
+}
+
+func TestNewCustomHandlerImpl_when_deleteWithoutRequestBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+
+	// WHEN
+	actual := NewCustomHandlerImpl(http.MethodDelete, "/a/{id}", handler)
+
+	// THEN
+	if actual.HasRequestBody() {
+		t.Errorf("Actual: '%t', expected: '%t'", actual.HasRequestBody(), false)
+	}
+}
+
+func TestNewCustomHandlerImpl_when_postWithRequestBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return JsonResponse(200, body)
+	}
+
+	// WHEN
+	actual := NewCustomHandlerImpl(http.MethodPost, "/a", handler)
+
+	// THEN
+	if !actual.HasRequestBody() {
+		t.Errorf("Actual: '%t', expected: '%t'", actual.HasRequestBody(), true)
+	}
+
+	if actual.GetRequestBodyType() != reflect.TypeOf(mockRequestBody{}) {
+		t.Errorf("Actual: '%s', expected: '%s'", actual.GetRequestBodyType(), reflect.TypeOf(mockRequestBody{}))
+	}
+}
+
+func TestNewCustomHandlerImpl_when_error_getWithRequestBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return JsonResponse(200, body)
+	}
+
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Expected a panic for a GET handler declaring a request body")
+		}
+	}()
+
+	// WHEN
+	NewCustomHandlerImpl(http.MethodGet, "/a", handler)
+}
+
+func TestRoutesANY_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		body, _ := h.RawBody()
+		return TextResponse(200, h.Request.Method + ":" + string(body))
+	}
+	dispatcher := NewDispatcher(NewRoutes().ANY("/a", handler), nil)
+
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+		var body string
+		if method != http.MethodGet {
+			body = "body"
+		}
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(method, "/a", strings.NewReader(body)))
+
+		// THEN
+		expected := method + ":" + body
+		if recorder.Code != http.StatusOK || recorder.Body.String() != expected {
+			t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, expected)
+		}
+	}
+}
+
+func TestRoutesANY_when_error_handlerHasRequestBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Expected a panic for an ANY handler declaring a request body")
+		}
+	}()
+
+	// WHEN
+	NewRoutes().ANY("/a", handler)
+}
+
+func TestDispatcher_when_error_contentLengthMismatch(t *testing.T) {
+	// GIVEN
+	handled := false
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		handled = true
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"a":1}`))
+	request.ContentLength = 42
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusBadRequest)
+	}
+
+	if handled {
+		t.Errorf("Actual: '%t', expected: '%t'", handled, false)
+	}
+}
+
+func TestDispatcher_when_contentLengthMatches(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return JsonResponse(200, body)
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"a":1}`)))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != `{"a":1}` {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, `{"a":1}`)
+	}
+}
+
+func TestHttpAddCookie_when_multipleCookies(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.AddCookie(&http.Cookie{Name: "a", Value: "1"})
+		h.AddCookie(&http.Cookie{Name: "b", Value: "2"})
+		return JsonResponse(200, nil)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("Actual: '%d', expected: '%d'", len(cookies), 2)
+	}
+
+	if cookies[0].Name != "a" || cookies[0].Value != "1" {
+		t.Errorf("Actual: '%s=%s', expected: '%s'", cookies[0].Name, cookies[0].Value, "a=1")
+	}
+
+	if cookies[1].Name != "b" || cookies[1].Value != "2" {
+		t.Errorf("Actual: '%s=%s', expected: '%s'", cookies[1].Name, cookies[1].Value, "b=2")
+	}
+}
+
+func TestJsonResponse_when_contentLength(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	JsonResponse(200, &mockRequestBody{A: 42}).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	expected := strconv.Itoa(recorder.Body.Len())
+	if actual := recorder.Header().Get("Content-Length"); actual != expected {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, expected)
+	}
+}
+
+func TestJsonResponse_when_error_unmarshallableBody(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	JsonResponse(200, make(chan int)).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestTextResponse_when_contentLength(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	TextResponse(200, "héllo").write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	expected := strconv.Itoa(recorder.Body.Len())
+	if actual := recorder.Header().Get("Content-Length"); actual != expected {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, expected)
+	}
+}
+
+func FuzzPathParsing(f *testing.F) {
+	// Seed corpus from the path tests above
+	for _, seed := range []string{
+		"",
+		"/",
+		"/{}",
+		"/{aa0}/{aa0}",
+		"/a/{mo-ck1}/bbb/{m-o-ck2}/a-b-c1/{mock3}",
+		"/a/111111/bbb/222222/a-b-c1/333333",
+		"/files/{p...}",
+		"/{p...}/a",
+		"/users/{id:int}/x/{u:uuid}",
+		"/users/{id:float}",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, path string) {
+		if ok, _ := isValidPath(path); !ok {
+			return
+		}
+
+		// Registration
+		pathVariables := extractPathVariableNames(path)
+		if declared := strings.Count(path, "{"); len(pathVariables) != declared {
+			t.Fatalf("Path '%s' => actual: '%d', expected: '%d' path variables", path, len(pathVariables), declared)
+		}
+		regex := toRegexPath(path, defaultPathVariablePattern)
+
+		// Matching a request path where each variable is replaced by a value of its type
+		parts := strings.Split(path, "/")
+		expectedValues := make(map[string]string)
+		for _, pathVariable := range pathVariables {
+			value := fmt.Sprintf("v%d", pathVariable.pathIndex + 1)
+			switch pathVariable.variableType {
+				case "int":
+					value = strconv.Itoa(pathVariable.pathIndex + 1)
+				case "uuid":
+					value = fmt.Sprintf("123e4567-e89b-12d3-a456-%012d", pathVariable.pathIndex + 1)
+			}
+			parts[pathVariable.pathIndex + 1] = value
+			expectedValues[pathVariable.variableName] = value
+		}
+		calledPath := strings.Join(parts, "/")
+		if !regex.MatchString(calledPath) {
+			t.Fatalf("Path '%s' => regex '%s' does not match '%s'", path, regex, calledPath)
+		}
+
+		// A catch-all matches the remainder of the path
+		catchAll := len(pathVariables) > 0 && pathVariables[len(pathVariables) - 1].catchAll
+		if !catchAll && regex.MatchString(calledPath + "/x") {
+			t.Fatalf("Path '%s' => regex '%s' matches '%s'", path, regex, calledPath + "/x")
+		}
+
+		values := extractPathVariableValues(regex, calledPath, pathVariables)
+		if len(values) != len(pathVariables) {
+			t.Fatalf("Path '%s' => actual: '%d', expected: '%d' path variable values", path, len(values), len(pathVariables))
+		}
+
+		for _, pathVariable := range pathVariables {
+			if expected := expectedValues[pathVariable.variableName]; values[pathVariable.variableName] != expected {
+				t.Fatalf("Path '%s' => actual: '%s', expected: '%s'", path, values[pathVariable.variableName], expected)
+			}
+		}
+	})
+}
+
+func TestDispatcher_when_error_handlerPanics(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		panic("mock panic")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a/42", nil))
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusInternalServerError)
+	}
+
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	if body.Path != "/a/42" || body.Method != http.MethodGet {
+		t.Errorf("Actual: '%s %s', expected: '%s %s'", body.Method, body.Path, http.MethodGet, "/a/42")
+	}
+}
+
+func TestDispatcher_when_error_handlerPanicsStackLogged(t *testing.T) {
+	// GIVEN
+	mock := useMockLogger(t)
+	handler := func(h *Http) HttpResponse {
+		var body *mockRequestBody
+		return TextResponse(http.StatusOK, strconv.Itoa(body.A))
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	logged := strings.Join(mock.debugs, "\n")
+	if !strings.Contains(logged, "nil pointer dereference") || !strings.Contains(logged, "runtime/debug.Stack") {
+		t.Errorf("Actual: '%v', expected: '%v'", logged, "the panic and its stack trace")
+	}
+}
+
+func TestDispatcher_when_error_preFilterPanics(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	filters := NewFilters().AddPreFilter(func(response http.ResponseWriter, request *http.Request) bool {
+		panic("mock panic")
+	})
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), filters)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusInternalServerError)
+	}
+
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	if body.Path != "/a" {
+		t.Errorf("Actual: '%s', expected: '%s'", body.Path, "/a")
+	}
+}
+
+func TestDispatcher_when_error_postFilterPanics(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "ok")
+	}
+	filters := NewFilters().AddPostFilter(func(response http.ResponseWriter, request *http.Request) bool {
+		panic("mock panic")
+	})
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), filters)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "ok" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "ok")
+	}
+}
+
+func TestDispatcher_when_error_handlerPanicsCustomPanicHandler(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		panic("mock panic")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.PanicHandler = func(h *Http, recovered interface{}) HttpResponse {
+		return TextResponse(http.StatusServiceUnavailable, fmt.Sprintf("%s %s %v", h.Request.URL.Path, RequestID(h.Request), recovered))
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("X-Request-ID", "42")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusServiceUnavailable)
+	}
+
+	if expected := "/a 42 mock panic"; recorder.Body.String() != expected {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), expected)
+	}
+}
+
+func TestDispatcher_when_error_tooManyHeaders(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.MaxHeaderCount = 2
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Add("X-A", "1")
+	request.Header.Add("X-A", "2")
+	request.Header.Add("X-B", "3")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusRequestHeaderFieldsTooLarge)
+	}
+}
+
+func TestDispatcher_when_error_headersTooLarge(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.MaxHeaderSize = 64
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("X-A", strings.Repeat("a", 64))
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusRequestHeaderFieldsTooLarge)
+	}
+}
+
+func TestDispatcher_when_headersUnderLimits(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.MaxHeaderCount = 2
+	dispatcher.MaxHeaderSize = 64
+
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("X-A", "1")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestDispatcher_when_defaultPathVariablePattern(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(200, h.PathVariables["name"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/files/{name}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/files/.hidden", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestDispatcher_when_customPathVariablePattern(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(200, h.PathVariables["name"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/files/{name}", handler), nil)
+	dispatcher.PathVariablePattern = `[a-zA-Z0-9_.-]+`
+	if err := dispatcher.Compile(); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/files/.hidden", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != ".hidden" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, ".hidden")
+	}
+}
+
+func TestDispatcherCompile_when_invalidPathVariablePattern(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(200, h.PathVariables["name"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/files/{name}", handler), nil)
+	dispatcher.PathVariablePattern = `[a-z`
+
+	// WHEN
+	err := dispatcher.Compile()
+
+	// THEN
+	if err == nil {
+		t.Errorf("Expected an error for an invalid pattern")
+	}
+
+	// The routes compiled by `NewDispatcher()` are kept
+	recorder := httptest.NewRecorder()
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/files/a", nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "a" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "a")
+	}
+}
+
+func TestHttpCanceled_when_contextIsCanceled(t *testing.T) {
+	// GIVEN
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a", nil).WithContext(ctx)}
+	cancel()
+
+	// WHEN
+	actual := h.Canceled()
+
+	// THEN
+	if actual == false {
+		t.Errorf("Actual: '%t', expected: '%t'", actual, true)
+	}
+}
+
+func TestHttpCanceled_when_contextIsActive(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a", nil)}
+
+	// WHEN
+	actual := h.Canceled()
+
+	// THEN
+	if actual == true {
+		t.Errorf("Actual: '%t', expected: '%t'", actual, false)
+	}
+}
+
+func TestHttpContext_when_nominal(t *testing.T) {
+	// GIVEN
+	ctx := context.WithValue(context.Background(), contextKey(42), "value")
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a", nil).WithContext(ctx)}
+
+	// WHEN
+	actual := h.Context()
+
+	// THEN
+	if actual.Value(contextKey(42)) != "value" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual.Value(contextKey(42)), "value")
+	}
+}
+
+func TestDispatcher_when_contextIsCanceled(t *testing.T) {
+	// GIVEN
+	handlerCalled, postFilterCalled := false, false
+	handler := func(h *Http) HttpResponse {
+		handlerCalled = true
+		return NoContentResponse()
+	}
+	filters := NewFilters().AddPostFilter(func(response http.ResponseWriter, request *http.Request) bool {
+		postFilterCalled = true
+		return true
+	})
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), filters)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil).WithContext(ctx))
+
+	// THEN
+	if handlerCalled || postFilterCalled {
+		t.Errorf("Actual: '%t %t', expected: '%t %t'", handlerCalled, postFilterCalled, false, false)
+	}
+}
+
+// Captures the messages logged through the package `log`
+type mockLogger struct {
+	debugs []string
+	infos []string
+}
+
+func (l *mockLogger) Debug(format string, args ...interface{}) {
+	l.debugs = append(l.debugs, fmt.Sprintf(format, args...))
+}
+
+func (l *mockLogger) Info(format string, args ...interface{}) {
+	l.infos = append(l.infos, fmt.Sprintf(format, args...))
+}
+
+// Replaces the package `log` until the test ends
+func useMockLogger(t *testing.T) *mockLogger {
+	mock := new(mockLogger)
+	previous := log
+	log = mock
+	t.Cleanup(func() {
+		log = previous
+	})
+
+	return mock
+}
+
+func TestSetLogger_when_notFound(t *testing.T) {
+	// GIVEN
+	mock := new(mockLogger)
+	previous := log
+	SetLogger(mock)
+	defer SetLogger(previous)
+	dispatcher := NewDispatcher(NewRoutes(), nil)
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	// THEN
+	expected := "[Dispatcher#getHandler] Route does NOT exists => Method: 'GET' | Path: '/unknown'"
+	found := false
+	for _, message := range mock.debugs {
+		found = found || message == expected
+	}
+	if !found {
+		t.Errorf("Actual: '%v', expected: '%v'", mock.debugs, expected)
+	}
+}
+
+func TestDispatcher_when_requestBodyNotLogged(t *testing.T) {
+	// GIVEN
+	mock := useMockLogger(t)
+	handler := func(h *Http, requestBody *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"password":"s3cr3t"}`))
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), request)
+
+	// THEN
+	for _, message := range append(mock.debugs, mock.infos...) {
+		if strings.Contains(message, "s3cr3t") {
+			t.Errorf("Actual: '%v', expected: '%v'", message, "no request body")
+		}
+	}
+}
+
+func TestDispatcher_when_logRequestBodies(t *testing.T) {
+	// GIVEN
+	mock := useMockLogger(t)
+	handler := func(h *Http, requestBody *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+	dispatcher.LogRequestBodies = true
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"password":"s3cr3t"}`))
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), request)
+
+	// THEN
+	found := false
+	for _, message := range mock.debugs {
+		found = found || strings.Contains(message, "s3cr3t")
+	}
+	if !found {
+		t.Errorf("Actual: '%v', expected: '%v'", mock.debugs, "the request body")
+	}
+}
+
+func TestSetLogger_when_error_nil(t *testing.T) {
+	// GIVEN
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Actual: '%v', expected: '%v'", "no panic", "panic")
+		}
+	}()
+
+	// WHEN
+	SetLogger(nil)
+}
+
+func TestDispatcher_when_structuredLog(t *testing.T) {
+	// GIVEN
+	mock := useMockLogger(t)
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusCreated, "ok")
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a/{id}", handler), nil)
+	dispatcher.StructuredLog = true
+
+	request := httptest.NewRequest(http.MethodPost, "/a/42", nil)
+	request.Header.Set("X-Request-ID", "req-1")
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), request)
+
+	// THEN
+	if len(mock.infos) != 1 {
+		t.Fatalf("Actual: '%d', expected: '%d'", len(mock.infos), 1)
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal([]byte(mock.infos[0]), &line); err != nil {
+		t.Fatalf("Unexpected error: '%s' for '%s'", err.Error(), mock.infos[0])
+	}
+
+	expected := map[string]interface{}{"method": "POST", "path": "/a/42", "status": 201.0, "request_id": "req-1"}
+	for key, value := range expected {
+		if line[key] != value {
+			t.Errorf("Actual: '%v', expected: '%v'", line[key], value)
+		}
+	}
+
+	if _, ok := line["duration_ms"].(float64); !ok {
+		t.Errorf("Actual: '%v', expected a number", line["duration_ms"])
+	}
+}
+
+func TestDispatcher_when_serverTiming(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		time.Sleep(2 * time.Millisecond)
+		return JsonResponse(http.StatusOK, "ok")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.ServerTiming = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	serverTiming := recorder.Header().Get("Server-Timing")
+	if !strings.HasPrefix(serverTiming, "app;dur=") {
+		t.Fatalf("Actual: '%v', expected: '%v'", serverTiming, "app;dur=<milliseconds>")
+	}
+
+	if duration, err := strconv.ParseFloat(strings.TrimPrefix(serverTiming, "app;dur="), 64); err != nil || duration < 2 {
+		t.Errorf("Actual: '%v', expected: '%v'", serverTiming, "a duration of at least 2ms")
+	}
+}
+
+func TestDispatcher_when_responseMiddleware(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, []string{"a"})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.ResponseMiddleware = []func(HttpResponse) HttpResponse{
+		func(response HttpResponse) HttpResponse {
+			return WithHeaders(response, map[string]string{"X-Version": "1", "X-Order": "first"})
+		},
+		func(response HttpResponse) HttpResponse {
+			return WithHeaders(response, map[string]string{"X-Order": "last"})
+		},
+	}
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	// The outermost decorator writes its headers first, the innermost overwrites them
+	for name, expected := range map[string]string{"X-Version": "1", "X-Order": "first"} {
+		if actual := recorder.Header().Get(name); actual != expected {
+			t.Errorf("%s => actual: '%v', expected: '%v'", name, actual, expected)
+		}
+	}
+
+	if recorder.Body.String() != `["a"]` {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), `["a"]`)
+	}
+}
+
+func TestDispatcher_when_serverTimingDisabled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Server-Timing"); actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+}
+
+func TestDispatcher_when_structuredLogIsDisabled(t *testing.T) {
+	// GIVEN
+	mock := useMockLogger(t)
+	dispatcher := NewDispatcher(NewRoutes(), nil)
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if len(mock.infos) != 0 {
+		t.Errorf("Actual: '%d', expected: '%d'", len(mock.infos), 0)
+	}
+}
+
+func TestRoutesList_when_descriptionAndTags(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	routes := NewRoutes().
+		POST("/users", handler).
+		GET("/users", handler, WithDescription("List users"), WithTags("users", "public")).
+		GET("/users/{id}", handler)
+
+	// WHEN
+	actual := routes.List()
+
+	// THEN
+	expected := []RouteInfo{
+		RouteInfo{Method: "GET", Path: "/users", Description: "List users", Tags: []string{"users", "public"}},
+		RouteInfo{Method: "GET", Path: "/users/{id}"},
+		RouteInfo{Method: "POST", Path: "/users"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual: '%+v', expected: '%+v'", actual, expected)
+	}
+}
+
+func TestHttpWriteError_when_nothingWritten(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.WriteError(http.StatusConflict, "mock error")
+		return nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusConflict)
+	}
+
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Message != "mock error" {
+		t.Errorf("Actual: '%s', expected a JSON error with message '%s'", recorder.Body.String(), "mock error")
+	}
+}
+
+func TestHttpWriteError_when_partiallyWritten(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.Response.Write([]byte("partial"))
+		h.WriteError(http.StatusInternalServerError, "mock error")
+		return nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	defer func() {
+		// THEN
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("Actual: '%v', expected: '%v'", recovered, http.ErrAbortHandler)
+		}
+
+		if recorder.Code != http.StatusOK || recorder.Body.String() != "partial" {
+			t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "partial")
+		}
+	}()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+}
+
+func TestDispatcherGetHandler_when_staticRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler).GET("/users", handler).GET("/a/b/c/d", handler), nil)
+
+	// WHEN
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := dispatcher.getHandler(http.MethodGet, "", "/a/b/c/d"); err != nil {
+			t.Fatalf("Unexpected error: '%s'", err.Error())
+		}
+	})
+
+	// THEN
+	if allocs != 0 {
+		t.Errorf("Actual: '%f', expected: '%d'", allocs, 0)
+	}
+}
+
+func TestDispatcherGetHandler_when_staticRouteRegisteredAfterVariableRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler).GET("/users", handler), nil)
+
+	// WHEN
+	actual, err := dispatcher.getHandler(http.MethodGet, "", "/users")
+
+	// THEN
+	if err != nil || actual.GetPath() != "/users" {
+		t.Errorf("Actual: '%v', expected: '%s'", actual, "/users")
+	}
+}
+
+func TestDispatcherGetHandler_when_staticRouteShadowedByEarlierVariableRoute(t *testing.T) {
+	// GIVEN
+	byID := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "id " + h.PathVariables["id"])
+	}
+	me := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "me")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", byID).GET("/users/me", me).GET("/teams/me", me).GET("/teams/{id}", byID), nil)
+
+	// The first registered route matching the path wins
+	for calledPath, expected := range map[string]string{"/users/me": "id me", "/users/42": "id 42", "/teams/me": "me", "/teams/42": "id 42"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, calledPath, nil))
+
+		// THEN
+		if recorder.Body.String() != expected {
+			t.Errorf("Path '%s' => actual: '%s', expected: '%s'", calledPath, recorder.Body.String(), expected)
+		}
+	}
+}
+
+func BenchmarkStaticRoute(b *testing.B) {
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler).GET("/users", handler).GET("/a/b/c/d", handler), nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dispatcher.getHandler(http.MethodGet, "", "/a/b/c/d")
+	}
+}
+
+func TestWithDefaultContentType_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(200, &mockRequestBody{A: 1})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithDefaultContentType("application/vnd.api+json")), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/vnd.api+json" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/vnd.api+json")
+	}
+}
+
+func TestWithDefaultContentType_when_handlerOverrides(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.Response.Header().Set("Content-Type", "application/hal+json")
+		return JsonResponse(200, &mockRequestBody{A: 1})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithDefaultContentType("application/vnd.api+json")), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/hal+json" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/hal+json")
+	}
+}
+
+func TestWithDefaultContentType_when_error(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) (HttpResponse, error) {
+		return nil, NotFound("x")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithDefaultContentType("application/xml")), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNotFound)
+	}
+
+	// The error body is JSON
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/json" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/json")
+	}
+}
+
+func TestWithDefaultContentType_when_decoratedResponse(t *testing.T) {
+	cases := map[string]HttpResponse{
+		"WithHeaders": WithHeaders(JsonResponse(200, &mockRequestBody{A: 1}), map[string]string{"Link": "</a?page=2>; rel=\"next\""}),
+		"WithLastModified": WithLastModified(JsonResponse(200, &mockRequestBody{A: 1}), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)),
+		"JsonEncodedResponse": JsonEncodedResponse(200, &mockRequestBody{A: 1})}
+
+	for name, response := range cases {
+		// GIVEN
+		handler := func(h *Http) HttpResponse {
+			return response
+		}
+		dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithDefaultContentType("application/vnd.api+json")), nil)
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+		// THEN
+		if actual := recorder.Header().Get("Content-Type"); actual != "application/vnd.api+json" {
+			t.Errorf("Case '%s' => actual: '%s', expected: '%s'", name, actual, "application/vnd.api+json")
+		}
+	}
+}
+
+func TestWithDefaultContentType_when_csvResponse(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return CsvResponse(200, [][]string{{"a", "b"}}, nil)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithDefaultContentType("application/vnd.api+json")), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/csv" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "text/csv")
+	}
+}
+
+func TestHttpQuery_when_singleParameter(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a?id=1", nil)}
+
+	// WHEN
+	first, all := h.Query("id"), h.QueryAll("id")
+
+	// THEN
+	if first != "1" || !reflect.DeepEqual(all, []string{"1"}) {
+		t.Errorf("Actual: '%s' '%v', expected: '%s' '%v'", first, all, "1", []string{"1"})
+	}
+}
+
+func TestHttpQuery_when_repeatedParameter(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a?id=1&x=y&id=2", nil)}
+
+	// WHEN
+	first, all := h.Query("id"), h.QueryAll("id")
+
+	// THEN
+	if first != "1" || !reflect.DeepEqual(all, []string{"1", "2"}) {
+		t.Errorf("Actual: '%s' '%v', expected: '%s' '%v'", first, all, "1", []string{"1", "2"})
+	}
+}
+
+func TestHttpQuery_when_absentParameter(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a?x=y", nil)}
+
+	// WHEN
+	first, all := h.Query("id"), h.QueryAll("id")
+
+	// THEN
+	if first != "" || all != nil {
+		t.Errorf("Actual: '%s' '%v', expected: '%s' '%v'", first, all, "", nil)
+	}
+}
+
+func TestHttpQueryParamDefault_when_nominal(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a?sort=name&filter=", nil)}
+
+	// WHEN
+	present, empty, absent := h.QueryParamDefault("sort", "id"), h.QueryParamDefault("filter", "all"), h.QueryParamDefault("order", "asc")
+
+	// THEN
+	if present != "name" || empty != "" || absent != "asc" {
+		t.Errorf("Actual: '%s' '%s' '%s', expected: '%s' '%s' '%s'", present, empty, absent, "name", "", "asc")
+	}
+}
+
+func TestHttpQueryParamInt_when_nominal(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a?limit=10", nil)}
+
+	// WHEN
+	actual, err := h.QueryParamInt("limit")
+
+	// THEN
+	if err != nil || actual != 10 {
+		t.Errorf("Actual: '%d' '%v', expected: '%d' '%v'", actual, err, 10, nil)
+	}
+}
+
+func TestHttpQueryParamInt_when_error(t *testing.T) {
+	for _, target := range []string{"/a", "/a?limit=ten"} {
+		// GIVEN
+		h := &Http{Request: httptest.NewRequest(http.MethodGet, target, nil)}
+
+		// WHEN
+		_, err := h.QueryParamInt("limit")
+
+		// THEN
+		var httpError *HTTPError
+		if !errors.As(err, &httpError) || httpError.Code != http.StatusBadRequest {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", err, target, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHttpQueryParam_when_cached(t *testing.T) {
+	// GIVEN
+	h := &Http{Request: httptest.NewRequest(http.MethodGet, "/a?id=1", nil)}
+	h.QueryParam("id")
+
+	// WHEN
+	h.Request.URL.RawQuery = "id=2"
+
+	// THEN
+	if actual := h.QueryParam("id"); actual != "1" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "1")
+	}
+}
+
+func TestDispatcher_when_cleanPath(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(200, h.PathVariables["id"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	dispatcher.CleanPath = true
+
+	for _, path := range []string{"/users//42", "//users/42", "/users/./42", "/x/../users/42"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		// THEN
+		if recorder.Code != http.StatusOK || recorder.Body.String() != "42" {
+			t.Errorf("Path '%s' => actual: '%d %s', expected: '%d %s'", path, recorder.Code, recorder.Body.String(), http.StatusOK, "42")
+		}
+	}
+}
+
+func TestDispatcher_when_cleanPathRedirect(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(200, h.PathVariables["id"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	dispatcher.CleanPath = true
+	dispatcher.CleanPathRedirect = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users//42?a=b", nil))
+
+	// THEN
+	if recorder.Code != http.StatusMovedPermanently {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusMovedPermanently)
+	}
+
+	if actual := recorder.Header().Get("Location"); actual != "/users/42?a=b" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "/users/42?a=b")
+	}
+}
+
+func TestDispatcher_when_trailingSlashRedirect(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, h.Request.URL.Path)
+	}
+	routes := NewRoutes().GET("/users", handler)
+
+	// The path grammar has no trailing slash, such routes are built manually
+	routes[http.MethodGet] = append(routes[http.MethodGet], &CustomHandlerImpl{
+		regexPath: regexp.MustCompile("^/docs/$"),
+		handlerValue: reflect.ValueOf(handler)})
+	dispatcher := NewDispatcher(routes, nil)
+	dispatcher.TrailingSlashRedirect = true
+
+	for target, expected := range map[string]string{"/users/?a=b": "/users?a=b", "/docs": "/docs/"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+		// THEN
+		if recorder.Code != http.StatusMovedPermanently {
+			t.Errorf("Target '%s' => actual: '%d', expected: '%d'", target, recorder.Code, http.StatusMovedPermanently)
+		}
+
+		if actual := recorder.Header().Get("Location"); actual != expected {
+			t.Errorf("Target '%s' => actual: '%s', expected: '%s'", target, actual, expected)
+		}
+	}
+}
+
+func TestDispatcher_when_trailingSlashRedirectAndMethodNotRouted(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, h.Request.URL.Path)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+	dispatcher.TrailingSlashRedirect = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users/", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestDispatcher_when_trailingSlashRedirectAndPost(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/users", handler), nil)
+	dispatcher.TrailingSlashRedirect = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users/", strings.NewReader(`{"name":"Alice"}`)))
+
+	// THEN
+	if recorder.Code != http.StatusPermanentRedirect {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusPermanentRedirect)
+	}
+
+	if actual := recorder.Header().Get("Location"); actual != "/users" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "/users")
+	}
+}
+
+func TestDispatcher_when_trailingSlashRedirectIsDisabled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, h.Request.URL.Path)
+	}
+	routes := NewRoutes().GET("/users", handler)
+
+	// The path grammar has no trailing slash, such routes are built manually
+	routes[http.MethodGet] = append(routes[http.MethodGet], &CustomHandlerImpl{
+		regexPath: regexp.MustCompile("^/docs/$"),
+		handlerValue: reflect.ValueOf(handler)})
+	dispatcher := NewDispatcher(routes, nil)
+
+	for _, target := range []string{"/users/", "/docs"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+		// THEN
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("Target '%s' => actual: '%d', expected: '%d'", target, recorder.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestDispatcher_when_cleanPathIsDisabled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(200, h.PathVariables["id"])
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users//42", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestCleanPath_when_trailingSlash(t *testing.T) {
+	// GIVEN
+	var path string = "//users//"
+
+	// WHEN
+	actual := cleanPath(path)
+
+	// THEN
+	if actual != "/users/" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "/users/")
+	}
+}
+
+func TestDispatcher_when_handlerReturnsString(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) string {
+		return "hello " + h.PathVariables["name"]
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/hello/{name}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/hello/world", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "hello world" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "hello world")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/plain" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "text/plain")
+	}
+}
+
+func TestNewCustomHandlerImpl_when_error_unsupportedReturnType(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) int {
+		return 42
+	}
+
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Expected a panic for a handler returning an 'int'")
+		}
+	}()
+
+	// WHEN
+	NewCustomHandlerImpl(http.MethodGet, "/a", handler)
+}
+
+func TestDispatcher_when_handlerReturnsBodyAndStatus(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) (interface{}, int) {
+		return &mockRequestBody{A: body.A + 1}, http.StatusCreated
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"a":1}`)))
+
+	// THEN
+	if recorder.Code != http.StatusCreated || recorder.Body.String() != `{"a":2}` {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusCreated, `{"a":2}`)
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/json" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/json")
+	}
+}
+
+func TestDispatcher_when_handlerReturnsBodylessStatus(t *testing.T) {
+	for _, statusCode := range []int{http.StatusNoContent, http.StatusNotModified} {
+		// GIVEN
+		handler := func(h *Http) (interface{}, int) {
+			return nil, statusCode
+		}
+		dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+		// THEN
+		if recorder.Code != statusCode || recorder.Body.Len() != 0 {
+			t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), statusCode, "")
+		}
+
+		if actual := recorder.Header().Get("Content-Length"); actual != "" {
+			t.Errorf("Actual: '%s', expected: '%s'", actual, "")
+		}
+	}
+}
+
+func TestNewCustomHandlerImpl_when_error_bodyWithoutIntStatus(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) (interface{}, string) {
+		return nil, ""
+	}
+
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Expected a panic for a handler returning '(interface{}, string)'")
+		}
+	}()
+
+	// WHEN
+	NewCustomHandlerImpl(http.MethodGet, "/a", handler)
+}
+
+func TestJsonErrorResponse_when_requestIDHeader(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("X-Request-ID", "req-1")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	JsonErrorResponse(http.StatusBadRequest, request, "mock error").write(recorder, request)
+
+	// THEN
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	if body.RequestID != "req-1" {
+		t.Errorf("Actual: '%s', expected: '%s'", body.RequestID, "req-1")
+	}
+}
+
+func TestXmlErrorResponse_when_requestIDContext(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	request.Header.Set("X-Request-ID", "from-header")
+	request = WithRequestID(request, "from-context")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	XmlErrorResponse(http.StatusBadRequest, request, "mock error").write(recorder, request)
+
+	// THEN
+	if !strings.Contains(recorder.Body.String(), "<RequestID>from-context</RequestID>") {
+		t.Errorf("Actual: '%s', expected to contain '%s'", recorder.Body.String(), "<RequestID>from-context</RequestID>")
+	}
+}
+
+func TestJsonErrorResponse_when_noRequestID(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	JsonErrorResponse(http.StatusBadRequest, request, "mock error").write(recorder, request)
+
+	// THEN
+	if strings.Contains(recorder.Body.String(), "RequestID") {
+		t.Errorf("Actual: '%s', expected no '%s'", recorder.Body.String(), "RequestID")
+	}
+}
+
+func TestDispatcher_when_queryStringIsIgnoredForMatching(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(200, h.Query("q"))
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/search", handler), nil)
+
+	for _, target := range []string{"/search?q=foo", "http://example.com/search?q=foo"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+		// THEN
+		if recorder.Code != http.StatusOK || recorder.Body.String() != "foo" {
+			t.Errorf("Target '%s' => actual: '%d %s', expected: '%d %s'", target, recorder.Code, recorder.Body.String(), http.StatusOK, "foo")
+		}
+	}
+}
+
+func TestCreatedResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodPost, "/users", nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	CreatedResponse("/users/42", &mockRequestBody{A: 42}, map[string]string{"X-Mock": "mock"}).write(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusCreated)
+	}
+
+	if actual := recorder.Header().Get("Location"); actual != "/users/42" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "/users/42")
+	}
+
+	if actual := recorder.Header().Get("X-Mock"); actual != "mock" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "mock")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/json" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "application/json")
+	}
+
+	if recorder.Body.String() != `{"a":42}` {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), `{"a":42}`)
+	}
+}
+
+func TestTooManyRequestsResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	TooManyRequestsResponse(1500 * time.Millisecond, 100, 0, map[string]string{"error": "slow down"}).write(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusTooManyRequests)
+	}
+
+	expectedHeaders := map[string]string{
+		"Retry-After": "2",
+		"X-RateLimit-Limit": "100",
+		"X-RateLimit-Remaining": "0",
+		"Content-Type": "application/json"}
+	for name, expected := range expectedHeaders {
+		if actual := recorder.Header().Get(name); actual != expected {
+			t.Errorf("Header '%s' => actual: '%s', expected: '%s'", name, actual, expected)
+		}
+	}
+
+	if recorder.Body.String() != `{"error":"slow down"}` {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), `{"error":"slow down"}`)
+	}
+}
+
+func TestTooManyRequestsResponse_when_noBody(t *testing.T) {
+	// GIVEN
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	TooManyRequestsResponse(30 * time.Second, 10, 3).write(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusTooManyRequests)
+	}
+
+	if actual := recorder.Header().Get("Retry-After"); actual != "30" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "30")
+	}
+
+	if actual := recorder.Header().Get("X-RateLimit-Remaining"); actual != "3" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "3")
+	}
+
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "")
+	}
+}
+
+func TestDispatcher_when_responseTransform(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, map[string]int{"a": 1})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	var actualContentType string
+	dispatcher.ResponseTransform = func(contentType string, body []byte) []byte {
+		actualContentType = contentType
+		return append([]byte("-----BEGIN-----\n"), append(body, "\n-----END-----"...)...)
+	}
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	expectedBody := "-----BEGIN-----\n{\"a\":1}\n-----END-----"
+	if recorder.Body.String() != expectedBody {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), expectedBody)
+	}
+
+	if actualContentLength := recorder.Header().Get("Content-Length"); actualContentLength != strconv.Itoa(len(expectedBody)) {
+		t.Errorf("Actual: '%v', expected: '%v'", actualContentLength, len(expectedBody))
+	}
+
+	if actualContentType != "application/json" {
+		t.Errorf("Actual: '%v', expected: '%v'", actualContentType, "application/json")
+	}
+}
+
+func TestResponseWriter_when_noResponseTransform(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	JsonResponse(http.StatusOK, map[string]int{"a": 1}).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Body.String() != "{\"a\":1}" {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), "{\"a\":1}")
+	}
+}
+
+func TestDispatcher_when_error_rejectGetBody(t *testing.T) {
+	// GIVEN
+	called := false
+	handler := func(h *Http) HttpResponse {
+		called = true
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.RejectGetBody = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", strings.NewReader(`{"a":1}`)))
+
+	// THEN
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusBadRequest)
+	}
+
+	if called {
+		t.Errorf("Actual: '%v', expected: '%v'", called, false)
+	}
+}
+
+func TestDispatcher_when_rejectGetBodyWithoutBody(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.RejectGetBody = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestCustomHandlerImplWriteHttpResponse_when_error_inputsMismatch(t *testing.T) {
+	// GIVEN
+	mock := useMockLogger(t)
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+	customHandler := NewCustomHandlerImpl(http.MethodPost, "/a", handler)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/a", nil)
+	h := &Http{Response: recorder, Request: request}
+	inputs := []reflect.Value{reflect.ValueOf(h), reflect.ValueOf(&struct{}{})}
+
+	// WHEN
+	customHandler.WriteHttpResponse(recorder, request, inputs)
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusInternalServerError)
+	}
+
+	expected := "Handler of route 'POST /a' called with (*rest.Http, *struct {}), expected signature: func(*rest.Http, *rest.mockRequestBody) rest.HttpResponse"
+	if len(mock.debugs) == 0 || !strings.Contains(mock.debugs[len(mock.debugs) - 1], expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", mock.debugs, expected)
+	}
+}
+
+// `httptest.ResponseRecorder.Result()` only reports the headers set before `WriteHeader()`
+func TestJsonResponse_when_headersSetBeforeWriteHeader(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	JsonResponse(http.StatusOK, map[string]int{"a": 1}).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	result := recorder.Result()
+	if result.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Actual: '%v', expected: '%v'", result.Header.Get("Content-Type"), "application/json")
+	}
+
+	if result.Header.Get("Content-Length") != "7" {
+		t.Errorf("Actual: '%v', expected: '%v'", result.Header.Get("Content-Length"), "7")
+	}
+}
+
+func TestTextResponse_when_headersSetBeforeWriteHeader(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	TextResponse(http.StatusOK, "ok").write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Result().Header.Get("Content-Type"); actual != "text/plain" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "text/plain")
+	}
+}
+
+func TestFileResponse_when_headersSetBeforeWriteHeader(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	FileResponse(http.StatusOK, "text/csv", "attachment", 0, strings.NewReader("a,b")).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Result().Header.Get("Content-Type"); actual != "text/csv" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "text/csv")
+	}
+}
+
+func TestFileResponse_when_contentLength(t *testing.T) {
+	// GIVEN
+	content := strings.Repeat("a", 1024)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	FileResponse(http.StatusOK, "text/plain", "attachment", len(content), strings.NewReader(content)).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Length"); actual != "1024" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "1024")
+	}
+}
+
+func TestFileResponse_when_negativeContentLength(t *testing.T) {
+	// GIVEN
+	content := strings.Repeat("a", fileBufferThreshold + 1)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	FileResponse(http.StatusOK, "text/plain", "attachment", -1, strings.NewReader(content)).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if _, exists := recorder.Header()["Content-Length"]; exists {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Header().Get("Content-Length"), "")
+	}
+
+	if recorder.Body.String() != content {
+		t.Errorf("Actual: '%d' bytes, expected: '%d' bytes", recorder.Body.Len(), len(content))
+	}
+}
+
+func TestFileResponse_when_smallFileOfUnknownLength(t *testing.T) {
+	for _, contentLength := range []int{0, -1} {
+		// GIVEN
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		FileResponse(http.StatusOK, "text/plain", "attachment", contentLength, strings.NewReader("abc")).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+		// THEN
+		if actual := recorder.Header().Get("Content-Length"); actual != "3" {
+			t.Errorf("Content length '%d' => actual: '%v', expected: '%v'", contentLength, actual, "3")
+		}
+
+		if recorder.Body.String() != "abc" {
+			t.Errorf("Content length '%d' => actual: '%v', expected: '%v'", contentLength, recorder.Body.String(), "abc")
+		}
+	}
+}
+
+func TestFileResponse_when_error_read(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	FileResponse(http.StatusOK, "text/plain", "attachment", 0, iotest.ErrReader(errors.New("disk failure"))).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestFileResponse_when_customHeaders(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+	customHeaders := map[string]string{"Cache-Control": "max-age=3600", "Content-Type": "text/html"}
+
+	// WHEN
+	FileResponse(http.StatusOK, "text/plain", "attachment", 3, strings.NewReader("abc"), customHeaders).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Cache-Control"); actual != "max-age=3600" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "max-age=3600")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/plain" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "text/plain")
+	}
+}
+
+func TestNoContentResponse_when_customHeaders(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	NoContentResponse(map[string]string{"Location": "/users/42"}).write(recorder, httptest.NewRequest(http.MethodPut, "/users/42", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+
+	if actual := recorder.Header().Get("Location"); actual != "/users/42" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "/users/42")
+	}
+}
+
+func TestDispatcherAllowedMethods_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	routes := NewRoutes().
+		PUT("/a/{id}", handler).
+		GET("/a/{id}", handler).
+		GET("/a/{name}", handler).
+		DELETE("/a/{id}", handler).
+		POST("/b/{id}", handler).
+		PATCH("/a/static", handler)
+	dispatcher := NewDispatcher(routes, nil)
+
+	// WHEN
+	actual := dispatcher.allowedMethods("", "/a/42")
+
+	// THEN
+	expected := []string{http.MethodDelete, http.MethodGet, http.MethodHead, http.MethodPut}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+}
+
+func TestDispatcherAllowedMethods_when_staticRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler).GET("/a", handler).GET("/a", handler), nil)
+
+	// WHEN
+	actual := dispatcher.allowedMethods("", "/a")
+
+	// THEN
+	expected := []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+}
+
+func TestDispatcherAllowedMethods_when_noMatch(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+
+	// WHEN
+	actual := dispatcher.allowedMethods("", "/b")
+
+	// THEN
+	if actual != nil {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, nil)
+	}
+}
+
+func TestWithRawBody_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		// Streaming the body in small chunks
+		var total int
+		buffer := make([]byte, 4)
+		for {
+			n, err := h.Request.Body.Read(buffer)
+			total += n
+			if err != nil {
+				break
+			}
+		}
+		return TextResponse(http.StatusOK, strconv.Itoa(total))
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/upload", handler, WithRawBody()), nil)
+	dispatcher.MaxBodySize = 100
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 1000))))
+
+	// THEN
+	if recorder.Body.String() != "1000" {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), "1000")
+	}
+}
+
+func TestWithRawBody_when_error_requestBodyParameter(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Expected a panic for a `WithRawBody()` handler declaring a request body")
+		}
+	}()
+
+	// WHEN
+	NewCustomHandlerImpl(http.MethodPost, "/upload", handler, WithRawBody())
+}
+
+func TestDispatcher_when_pathPrefixOfRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users", handler), nil)
+
+	for _, calledPath := range []string{"/users/5", "/xusersx", "/users-admin"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, calledPath, nil))
+
+		// THEN
+		if recorder.Code != http.StatusNotFound {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", recorder.Code, calledPath, http.StatusNotFound)
+		}
+	}
+}
+
+func TestDispatcher_when_pathPrefixOfRouteWithPathVariable(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users/5/orders", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestDispatcher_when_error_methodNotAllowed(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().PUT("/users/{id}", handler).GET("/users/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users/5", nil))
+
+	// THEN
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusMethodNotAllowed)
+	}
+
+	if actual := recorder.Header().Get("Allow"); actual != "GET, HEAD, PUT" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "GET, HEAD, PUT")
+	}
+}
+
+func TestRoutesInclude_when_nominal(t *testing.T) {
+	// GIVEN
+	registerUsers := func(routes Routes) {
+		routes.GET("/users/{id}", func(h *Http) HttpResponse {
+			return TextResponse(http.StatusOK, "user " + h.PathVariables["id"])
+		})
+	}
+	registerOrders := func(routes Routes) {
+		routes.GET("/orders", func(h *Http) HttpResponse {
+			return TextResponse(http.StatusOK, "orders")
+		})
+	}
+	dispatcher := NewDispatcher(NewRoutes().Include(registerUsers, registerOrders), nil)
+
+	for calledPath, expected := range map[string]string{"/users/42": "user 42", "/orders": "orders"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, calledPath, nil))
+
+		// THEN
+		if recorder.Body.String() != expected {
+			t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), expected)
+		}
+	}
+}
+
+func TestRoutesGETIf_when_disabled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "debug")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GETIf(false, "/debug", handler).GETIf(true, "/admin", handler), nil)
+
+	for calledPath, expected := range map[string]int{"/debug": http.StatusNotFound, "/admin": http.StatusOK} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, calledPath, nil))
+
+		// THEN
+		if recorder.Code != expected {
+			t.Errorf("Path '%s' => actual: '%v', expected: '%v'", calledPath, recorder.Code, expected)
+		}
+	}
+}
+
+func TestRoutesDELETEIf_when_disabled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+
+	// WHEN
+	routes := NewRoutes().DELETEIf(false, "/cache", handler)
+
+	// THEN
+	if len(routes.List()) != 0 {
+		t.Errorf("Actual: '%v', expected: '%v'", routes.List(), "no route")
+	}
+}
+
+func TestDispatcher_when_autoOptions(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/x", handler).POST("/x", handler), nil)
+	dispatcher.AutoOptions = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodOptions, "/x", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+
+	// HEAD is answered by the GET route
+	if actual := recorder.Header().Get("Allow"); actual != "GET, HEAD, OPTIONS, POST" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "GET, HEAD, OPTIONS, POST")
+	}
+}
+
+func TestDispatcher_when_autoOptionsAndOptionsRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "custom")
+	}
+	routes := NewRoutes().GET("/x", handler)
+	if err := routes.AddRouteE(http.MethodOptions, "/x", handler); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	dispatcher := NewDispatcher(routes, nil)
+	dispatcher.AutoOptions = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodOptions, "/x", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "custom" {
+		t.Errorf("Actual: '%v %v', expected: '%v %v'", recorder.Code, recorder.Body.String(), http.StatusOK, "custom")
+	}
+}
+
+func TestDispatcher_when_autoOptionsDisabled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/x", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodOptions, "/x", nil))
+
+	// THEN
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestDispatcher_when_headOnGetRoute(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return JsonResponse(http.StatusOK, map[string]string{"id": h.PathVariables["id"]})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/users/42", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusOK)
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "application/json" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "application/json")
+	}
+
+	// The Content-Length of the GET response
+	if actual := recorder.Header().Get("Content-Length"); actual != "11" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "11")
+	}
+
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), "")
+	}
+}
+
+func TestDispatcher_when_headRouteRegistered(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes().GET("/a", func(h *Http) HttpResponse {
+		return TextResponse(http.StatusOK, "get")
+	})
+	if err := routes.AddRouteE(http.MethodHead, "/a", func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}); err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+	dispatcher := NewDispatcher(routes, nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodHead, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestDispatcher_when_error_pathNotFound(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/orders/5", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNotFound)
+	}
+
+	if actual := recorder.Header().Get("Allow"); actual != "" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "")
+	}
+}
+
+func TestHttpResponse_when_nilCustomHeaders(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/a", nil)
+	responses := map[string]HttpResponse{
+		"JsonResponse": JsonResponse(http.StatusOK, map[string]int{"a": 1}),
+		"XmlResponse": XmlResponse(http.StatusOK, ErrorResponse{Message: "a"}),
+		"CreatedResponse": CreatedResponse("/a/1", map[string]int{"a": 1}, nil),
+		"JsonErrorResponse": JsonErrorResponse(http.StatusBadRequest, request, "a"),
+		"XmlErrorResponse": XmlErrorResponse(http.StatusBadRequest, request, "a"),
+		"MarshaledResponse": MarshaledResponse(http.StatusOK, "application/json", map[string]int{"a": 1}),
+		"FileResponse": FileResponse(http.StatusOK, "text/plain", "attachment", 1, strings.NewReader("a")),
+		"NoContentResponse": NoContentResponse(),
+		"TextResponse": TextResponse(http.StatusOK, "a"),
+		"WithHeaders": WithHeaders(TextResponse(http.StatusOK, "a"), nil),
+	}
+
+	for name, response := range responses {
+		// GIVEN
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		func() {
+			defer func() {
+				// THEN
+				if recovered := recover(); recovered != nil {
+					t.Errorf("Actual: '%v' for '%s', expected: '%v'", recovered, name, nil)
+				}
+			}()
+			response.write(recorder, request)
+		}()
+
+		// THEN
+		if recorder.Code == http.StatusInternalServerError {
+			t.Errorf("Actual: '%v' for '%s', expected: a successful write", recorder.Code, name)
+		}
+	}
+}
+
+func TestRedirectResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	RedirectResponse(http.StatusSeeOther, "/users/42").write(recorder, httptest.NewRequest(http.MethodPost, "/users", nil))
+
+	// THEN
+	result := recorder.Result()
+	if result.StatusCode != http.StatusSeeOther {
+		t.Errorf("Actual: '%v', expected: '%v'", result.StatusCode, http.StatusSeeOther)
+	}
+
+	if actual := result.Header.Get("Location"); actual != "/users/42" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "/users/42")
+	}
+
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), "")
+	}
+}
+
+func TestRedirectResponse_when_methodPreservingStatuses(t *testing.T) {
+	for _, statusCode := range []int{http.StatusMovedPermanently, http.StatusTemporaryRedirect, http.StatusPermanentRedirect} {
+		// GIVEN
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		RedirectResponse(statusCode, "/v2/users").write(recorder, httptest.NewRequest(http.MethodPut, "/v1/users", nil))
+
+		// THEN
+		if recorder.Code != statusCode || recorder.Header().Get("Location") != "/v2/users" || recorder.Body.Len() != 0 {
+			t.Errorf("Actual: '%d %s %q', expected: '%d %s %q'", recorder.Code, recorder.Header().Get("Location"), recorder.Body.String(), statusCode, "/v2/users", "")
+		}
+	}
+}
+
+func TestRedirectResponse_when_error_notRedirectStatus(t *testing.T) {
+	for _, statusCode := range []int{http.StatusOK, http.StatusMultipleChoices, http.StatusNotModified} {
+		func() {
+			defer func() {
+				// THEN
+				if recover() == nil {
+					t.Errorf("Expected a panic for status %d", statusCode)
+				}
+			}()
+
+			// GIVEN / WHEN
+			RedirectResponse(statusCode, "/users/42")
+		}()
+	}
+}
+
+func TestDispatcher_when_handlerReturnsNil(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestDispatcher_when_handlerReturnsNilCustomDefaultResponse(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) (HttpResponse, error) {
+		return nil, nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.DefaultResponse = TextResponse(http.StatusAccepted, "nothing to do")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusAccepted || recorder.Body.String() != "nothing to do" {
+		t.Errorf("Actual: '%v %v', expected: '%v %v'", recorder.Code, recorder.Body.String(), http.StatusAccepted, "nothing to do")
+	}
+}
+
+func TestDispatcher_when_handlerWritesItselfAndReturnsNil(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.Response.WriteHeader(http.StatusTeapot)
+		return nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusTeapot {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusTeapot)
+	}
+}
+
+func TestRoutesAddRouteE_when_nominal(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return NoContentResponse()
+	}
+
+	// WHEN
+	err := routes.AddRouteE(http.MethodPost, "/a/{id}", handler, WithDescription("Creates an A"))
+
+	// THEN
+	if err != nil {
+		t.Fatalf("Unexpected error: '%s'", err.Error())
+	}
+
+	if len(routes[http.MethodPost]) != 1 || routes[http.MethodPost][0].GetDescription() != "Creates an A" {
+		t.Errorf("Actual: '%v', expected: '%v'", routes[http.MethodPost], "the registered route")
+	}
+}
+
+func TestRoutesAddRouteE_when_error(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+		handler interface{}
+		expected string
+	}{
+		{"wrong arity", "/a", func(h *Http, a *mockRequestBody, b *mockRequestBody) HttpResponse { return nil }, "must have 1 or 2 input parameters but had 3"},
+		{"wrong first parameter", "/a", func(h Http) HttpResponse { return nil }, "parameter n°1 type must be '*rest.Http' but was 'rest.Http'"},
+		{"non-pointer request body", "/a", func(h *Http, body mockRequestBody) HttpResponse { return nil }, "parameter n°2 type must be a pointer"},
+		{"invalid path", "/a//b", func(h *Http) HttpResponse { return nil }, "didn't matched regex pattern"},
+		{"nil handler", "/a", nil, "handler must not be `nil`"},
+	}
+
+	for _, c := range cases {
+		// GIVEN
+		routes := NewRoutes()
+
+		// WHEN
+		err := routes.AddRouteE(http.MethodPost, c.path, c.handler)
+
+		// THEN
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", err, c.name, c.expected)
+		}
+
+		if len(routes) != 0 {
+			t.Errorf("Actual: '%v' for '%s', expected: no route", routes, c.name)
+		}
+	}
+}
+
+func TestRoutesAddRouteE_when_error_deleteWithoutRequestBody(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	handler := func(h *Http, id string) HttpResponse {
+		return NoContentResponse()
+	}
+
+	// WHEN
+	err := routes.AddRouteE(http.MethodDelete, "/users/{id}", handler)
+
+	// THEN
+	expected := "Without request body, the DELETE handler only takes '*rest.Http'"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Actual: '%v', expected: '%v'", err, expected)
+	}
+}
+
+func TestRoutesAddRouteE_when_deleteWithoutRequestBody(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes()
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+
+	// WHEN
+	err := routes.AddRouteE(http.MethodDelete, "/users/{id}", handler)
+
+	// THEN
+	if err != nil {
+		t.Errorf("Actual: '%v', expected: '%v'", err, nil)
+	}
+}
+
+func TestHandlerResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	standardHandler := http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.Header().Set("X-Delegated", "true")
+		response.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(response, "%s %s", request.Method, request.URL.Path)
+	})
+	handler := func(h *Http) HttpResponse {
+		return HandlerResponse(standardHandler)
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/legacy/{id}", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/legacy/42", nil))
+
+	// THEN
+	if recorder.Code != http.StatusAccepted || recorder.Body.String() != "GET /legacy/42" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusAccepted, "GET /legacy/42")
+	}
+
+	if actual := recorder.Header().Get("X-Delegated"); actual != "true" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "true")
+	}
+}
+
+func TestDispatcher_when_error_bodyTooLarge(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return JsonResponse(http.StatusOK, body)
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+	dispatcher.MaxBodySize = 16
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"a":1234567890123}`)))
+
+	// THEN
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestDispatcher_when_error_bodyTooLargeUnknownLength(t *testing.T) {
+	for _, path := range []string{"/a", "/raw"} {
+		// GIVEN
+		handler := func(h *Http, body *mockRequestBody) HttpResponse {
+			return JsonResponse(http.StatusOK, body)
+		}
+		rawHandler := func(h *Http) (HttpResponse, error) {
+			body, err := h.RawBody()
+			if err != nil {
+				return nil, err
+			}
+			return TextResponse(http.StatusOK, string(body)), nil
+		}
+		dispatcher := NewDispatcher(NewRoutes().POST("/a", handler).POST("/raw", rawHandler), nil)
+		dispatcher.MaxBodySize = 16
+		request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"a":1234567890123}`))
+		request.ContentLength = -1
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, request)
+
+		// THEN
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Actual: '%v' for '%s', expected: '%v'", recorder.Code, path, http.StatusRequestEntityTooLarge)
+		}
+	}
+}
+
+func TestDispatcher_when_bodyUnderMaxSize(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		return JsonResponse(http.StatusOK, body)
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+	dispatcher.MaxBodySize = 16
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"a":123456789}`)))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != `{"a":123456789}` {
+		t.Errorf("Actual: '%v %v', expected: '%v %v'", recorder.Code, recorder.Body.String(), http.StatusOK, `{"a":123456789}`)
+	}
+}
+
+func TestDispatcher_when_error_invalidJsonBody(t *testing.T) {
+	// GIVEN
+	called := false
+	handler := func(h *Http, body *mockRequestBody) HttpResponse {
+		called = true
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().POST("/a", handler), nil)
+	request := httptest.NewRequest(http.MethodPost, "/a", strings.NewReader(`{"a":`))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusBadRequest)
+	}
+
+	var body ErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Message == "" {
+		t.Errorf("Actual: '%v' (%v), expected: '%v'", recorder.Body.String(), err, "a JSON error body")
+	}
+
+	if called {
+		t.Errorf("Actual: '%v', expected: '%v'", called, false)
+	}
+}
@@ -0,0 +1,25 @@
+// Test helpers for applications using `rest`, kept out of the `rest` package so that production binaries don't link
+// `net/http/httptest`
+package resttest
+
+import (
+	"net/http/httptest"
+
+	"github.com/eau-de-la-seine/golang-rest"
+)
+
+// Runs the filter alone against a request built from `method`, `path` and `headers` (can be nil),
+// returns the recorded response and the filter's result
+func RunFilter(f rest.FilterFunc, method, path string, headers map[string]string) (*httptest.ResponseRecorder, bool) {
+	if f == nil {
+		panic("[RunFilter] filter must not be `nil`")
+	}
+
+	request := httptest.NewRequest(method, path, nil)
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+
+	return recorder, f(recorder, request)
+}
@@ -0,0 +1,60 @@
+package resttest
+
+import (
+	"testing"
+	"net/http"
+)
+
+func mockAuthFilter(response http.ResponseWriter, request *http.Request) bool {
+	if request.Header.Get("Authorization") != "Bearer token" {
+		response.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+func TestRunFilter_when_filterPasses(t *testing.T) {
+	// WHEN
+	recorder, actual := RunFilter(mockAuthFilter, http.MethodGet, "/a", map[string]string{"Authorization": "Bearer token"})
+
+	// THEN
+	if !actual {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, true)
+	}
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestRunFilter_when_filterStopsTreatment(t *testing.T) {
+	// WHEN
+	recorder, actual := RunFilter(mockAuthFilter, http.MethodGet, "/a", nil)
+
+	// THEN
+	if actual {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, false)
+	}
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRunFilter_when_requestIsBuilt(t *testing.T) {
+	// GIVEN
+	var actualMethod, actualPath, actualQuery string
+	filter := func(response http.ResponseWriter, request *http.Request) bool {
+		actualMethod, actualPath, actualQuery = request.Method, request.URL.Path, request.URL.Query().Get("q")
+		return true
+	}
+
+	// WHEN
+	RunFilter(filter, http.MethodPost, "/a/b?q=1", nil)
+
+	// THEN
+	if actualMethod != http.MethodPost || actualPath != "/a/b" || actualQuery != "1" {
+		t.Errorf("Actual: '%s %s %s', expected: '%s %s %s'", actualMethod, actualPath, actualQuery, http.MethodPost, "/a/b", "1")
+	}
+}
@@ -0,0 +1,32 @@
+// Optional YAML codec, kept out of the `rest` package so that only the applications importing it depend on
+// `gopkg.in/yaml.v3`. Importing it registers the codec:
+//
+//	import "github.com/eau-de-la-seine/golang-rest/restyaml"
+package restyaml
+
+import (
+	"github.com/eau-de-la-seine/golang-rest"
+	"gopkg.in/yaml.v3"
+)
+
+// Content-Type of `YamlResponse()`
+const ContentType = "application/x-yaml"
+
+// Request bodies of these Content-Types are decoded as YAML
+var contentTypes = []string{ContentType, "application/yaml"}
+
+func init() {
+	for _, contentType := range contentTypes {
+		rest.RegisterCodec(contentType, yaml.Marshal, yaml.Unmarshal)
+	}
+}
+
+// `customHeaders` can be nil
+func YamlResponse(statusCode int, body interface{}, customHeaders map[string]string) rest.HttpResponse {
+	response := rest.MarshaledResponse(statusCode, ContentType, body)
+	if customHeaders == nil {
+		return response
+	}
+
+	return rest.WithHeaders(response, customHeaders)
+}
@@ -0,0 +1,65 @@
+package restyaml
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/eau-de-la-seine/golang-rest"
+	"gopkg.in/yaml.v3"
+)
+
+type mockUser struct {
+	Name string `yaml:"name"`
+	Age int `yaml:"age"`
+}
+
+func TestYamlResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *rest.Http) rest.HttpResponse {
+		return YamlResponse(http.StatusOK, &mockUser{Name: "Jane", Age: 42}, map[string]string{"X-Mock": "mock"})
+	}
+	dispatcher := rest.NewDispatcher(rest.NewRoutes().GET("/user", handler), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/user", nil))
+
+	// THEN
+	if actual := recorder.Header().Get("Content-Type"); actual != ContentType {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, ContentType)
+	}
+
+	if actual := recorder.Header().Get("X-Mock"); actual != "mock" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "mock")
+	}
+
+	actual := new(mockUser)
+	if err := yaml.Unmarshal(recorder.Body.Bytes(), actual); err != nil || *actual != (mockUser{Name: "Jane", Age: 42}) {
+		t.Errorf("Actual: '%+v' (%v), expected: '%+v'", *actual, err, mockUser{Name: "Jane", Age: 42})
+	}
+}
+
+func TestYamlRequestBody_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *rest.Http, user *mockUser) rest.HttpResponse {
+		return YamlResponse(http.StatusOK, user, nil)
+	}
+	dispatcher := rest.NewDispatcher(rest.NewRoutes().POST("/user", handler), nil)
+
+	for _, contentType := range []string{"application/x-yaml", "application/yaml"} {
+		request := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader("name: Jane\nage: 42\n"))
+		request.Header.Set("Content-Type", contentType)
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, request)
+
+		// THEN
+		expected := "name: Jane\nage: 42\n"
+		if recorder.Code != http.StatusOK || recorder.Body.String() != expected {
+			t.Errorf("Content-Type '%s' => actual: '%d %s', expected: '%d %s'", contentType, recorder.Code, recorder.Body.String(), http.StatusOK, expected)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Where `Http.Bind()` looks a field's value up
+const (
+	// Among the path variables then the query parameters, by field name (case-insensitive)
+	fieldSourceName = iota
+	// `path:"name"` tag
+	fieldSourcePath
+	// `query:"name"` tag
+	fieldSourceQuery
+)
+
+type fieldSchema struct {
+	index int
+	name string
+	source int
+	// Tag value, or the field name for `fieldSourceName`
+	key string
+	// `form:"name"` tag, empty if absent (see `unmarshalForm()`)
+	formKey string
+}
+
+// Metadata derived once per struct type, instead of reflecting over its fields on every request
+type structSchema struct {
+	// Exported fields only
+	fields []fieldSchema
+}
+
+// reflect.Type => *structSchema
+var schemas sync.Map
+
+func schemaOf(structType reflect.Type) *structSchema {
+	if cached, exists := schemas.Load(structType); exists {
+		return cached.(*structSchema)
+	}
+
+	schema := new(structSchema)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			// Unexported field
+			continue
+		}
+
+		fieldSchema := fieldSchema{index: i, name: field.Name, source: fieldSourceName, key: field.Name, formKey: field.Tag.Get("form")}
+		if name, exists := field.Tag.Lookup("path"); exists {
+			fieldSchema.source, fieldSchema.key = fieldSourcePath, name
+		} else if name, exists := field.Tag.Lookup("query"); exists {
+			fieldSchema.source, fieldSchema.key = fieldSourceQuery, name
+		}
+		schema.fields = append(schema.fields, fieldSchema)
+	}
+
+	// Another goroutine may have stored it meanwhile, both are equivalent
+	cached, _ := schemas.LoadOrStore(structType, schema)
+	return cached.(*structSchema)
+}
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"testing"
+	"reflect"
+)
+
+func TestSchemaOf_when_nominal(t *testing.T) {
+	// GIVEN
+	structType := reflect.TypeOf(mockBindTarget{})
+
+	// WHEN
+	schema := schemaOf(structType)
+
+	// THEN
+	expected := []fieldSchema{
+		{index: 0, name: "ID", source: fieldSourcePath, key: "id"},
+		{index: 1, name: "Limit", source: fieldSourceQuery, key: "limit"},
+		{index: 2, name: "Sort", source: fieldSourceName, key: "Sort"},
+		{index: 3, name: "Verbose", source: fieldSourceName, key: "Verbose"}}
+	if !reflect.DeepEqual(schema.fields, expected) {
+		t.Errorf("Actual: '%+v', expected: '%+v'", schema.fields, expected)
+	}
+}
+
+func TestSchemaOf_when_cached(t *testing.T) {
+	// GIVEN
+	structType := reflect.TypeOf(mockBindTarget{})
+	first := schemaOf(structType)
+
+	// WHEN
+	second := schemaOf(structType)
+
+	// THEN
+	if first != second {
+		t.Errorf("Actual: '%p', expected: '%p'", second, first)
+	}
+}
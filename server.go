@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Default timeouts of `NewServer()`
+const (
+	DefaultReadTimeout = 10 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+	DefaultIdleTimeout = 60 * time.Second
+	DefaultMaxHeaderBytes = 1 << 20
+)
+
+// Optional server configuration, passed to `NewServer()`
+type ServerOption func(*http.Server)
+
+func WithReadTimeout(timeout time.Duration) ServerOption {
+	return func(s *http.Server) {
+		s.ReadTimeout = timeout
+	}
+}
+
+func WithReadHeaderTimeout(timeout time.Duration) ServerOption {
+	return func(s *http.Server) {
+		s.ReadHeaderTimeout = timeout
+	}
+}
+
+func WithWriteTimeout(timeout time.Duration) ServerOption {
+	return func(s *http.Server) {
+		s.WriteTimeout = timeout
+	}
+}
+
+func WithIdleTimeout(timeout time.Duration) ServerOption {
+	return func(s *http.Server) {
+		s.IdleTimeout = timeout
+	}
+}
+
+// Returns an `http.Server` serving the dispatcher, with sane default timeouts
+func NewServer(addr string, dispatcher *Dispatcher, options ...ServerOption) *http.Server {
+	if dispatcher == nil {
+		panic("[NewServer] dispatcher must not be `nil`")
+	}
+
+	server := &http.Server{
+		Addr: addr,
+		Handler: dispatcher,
+		ReadTimeout: DefaultReadTimeout,
+		ReadHeaderTimeout: DefaultReadHeaderTimeout,
+		WriteTimeout: DefaultWriteTimeout,
+		IdleTimeout: DefaultIdleTimeout,
+		MaxHeaderBytes: DefaultMaxHeaderBytes}
+
+	for _, option := range options {
+		option(server)
+	}
+
+	return server
+}
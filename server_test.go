@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewServer_when_defaults(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes(), nil)
+
+	// WHEN
+	server := NewServer(":8080", dispatcher)
+
+	// THEN
+	if server.Addr != ":8080" || server.Handler != dispatcher {
+		t.Errorf("Actual: '%s' '%v', expected: '%s' '%v'", server.Addr, server.Handler, ":8080", dispatcher)
+	}
+
+	if server.ReadTimeout != DefaultReadTimeout || server.ReadHeaderTimeout != DefaultReadHeaderTimeout ||
+		server.WriteTimeout != DefaultWriteTimeout || server.IdleTimeout != DefaultIdleTimeout {
+		t.Errorf("Actual: '%s' '%s' '%s' '%s', expected the default timeouts", server.ReadTimeout, server.ReadHeaderTimeout, server.WriteTimeout, server.IdleTimeout)
+	}
+}
+
+func TestNewServer_when_options(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes(), nil)
+
+	// WHEN
+	server := NewServer(":8080", dispatcher,
+		WithReadTimeout(1 * time.Second),
+		WithReadHeaderTimeout(2 * time.Second),
+		WithWriteTimeout(3 * time.Second),
+		WithIdleTimeout(4 * time.Second))
+
+	// THEN
+	if server.ReadTimeout != 1 * time.Second {
+		t.Errorf("Actual: '%s', expected: '%s'", server.ReadTimeout, 1 * time.Second)
+	}
+
+	if server.ReadHeaderTimeout != 2 * time.Second {
+		t.Errorf("Actual: '%s', expected: '%s'", server.ReadHeaderTimeout, 2 * time.Second)
+	}
+
+	if server.WriteTimeout != 3 * time.Second {
+		t.Errorf("Actual: '%s', expected: '%s'", server.WriteTimeout, 3 * time.Second)
+	}
+
+	if server.IdleTimeout != 4 * time.Second {
+		t.Errorf("Actual: '%s', expected: '%s'", server.IdleTimeout, 4 * time.Second)
+	}
+}
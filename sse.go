@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Event of an `EventStreamResponse`, only `Data` is required
+type ServerSentEvent struct {
+	// Sent as `id:`, the client sends back the last one in the `Last-Event-ID` header when it reconnects.
+	// Line breaks are removed.
+	ID string
+	// Sent as `event:`, the type of the event (`message` if empty). Line breaks are removed.
+	Event string
+	// Sent as `data:`, one line per line of `Data` (`\n`, `\r\n` or `\r`)
+	Data string
+}
+
+// HTTP RESPONSE (SERVER-SENT EVENTS)
+type EventStreamResponseWriter struct {
+	events <-chan ServerSentEvent
+}
+
+func (r *EventStreamResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	response.Header().Set("Content-Type", "text/event-stream")
+	response.Header().Set("Cache-Control", "no-cache")
+	response.Header().Set("Connection", "keep-alive")
+
+	response.WriteHeader(http.StatusOK)
+	flush(response)
+
+	timeout := streamWriteTimeout(request)
+	defer resetWriteDeadline(response, timeout)
+	for {
+		select {
+			case <-request.Context().Done():
+				log.Debug("[EventStreamResponseWriter#write] => %s", request.Context().Err().Error())
+				return
+			case event, ok := <-r.events:
+				if !ok {
+					return
+				}
+
+				if _, err := writeAndFlush(response, formatEvent(event), timeout); err != nil {
+					log.Debug("[EventStreamResponseWriter#write] response.Write => %s", err.Error())
+					return
+				}
+		}
+	}
+}
+
+// Line breaks of the single-line fields, which would start another field or event
+var lineBreakRemover = strings.NewReplacer("\r", "", "\n", "")
+
+// Line breaks of `Data`, normalized before splitting it into `data:` lines
+var lineBreakNormalizer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// Ex: `id: 1\nevent: update\ndata: line 1\ndata: line 2\n\n`
+func formatEvent(event ServerSentEvent) []byte {
+	var builder strings.Builder
+	if id := lineBreakRemover.Replace(event.ID); id != "" {
+		fmt.Fprintf(&builder, "id: %s\n", id)
+	}
+
+	if eventType := lineBreakRemover.Replace(event.Event); eventType != "" {
+		fmt.Fprintf(&builder, "event: %s\n", eventType)
+	}
+
+	for _, line := range strings.Split(lineBreakNormalizer.Replace(event.Data), "\n") {
+		fmt.Fprintf(&builder, "data: %s\n", line)
+	}
+	builder.WriteString("\n")
+
+	return []byte(builder.String())
+}
+
+// Server-Sent Events: streams the events pushed to `events` until it's closed or the request's context is done
+// (client disconnected). Each event is flushed as soon as it's written.
+func EventStreamResponse(events <-chan ServerSentEvent) HttpResponse {
+	if events == nil {
+		panic("[EventStreamResponse] events must not be `nil`")
+	}
+
+	return &EventStreamResponseWriter{events: events}
+}
@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"testing"
+	"context"
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestEventStreamResponse_when_contextCanceled(t *testing.T) {
+	// GIVEN
+	recorder := &flushCountingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	ctx, cancel := context.WithCancel(context.Background())
+	request := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+	events := make(chan ServerSentEvent)
+	go func() {
+		events <- ServerSentEvent{Data: "first"}
+		events <- ServerSentEvent{ID: "2", Event: "update", Data: "line 1\nline 2"}
+		cancel()
+	}()
+
+	// WHEN
+	EventStreamResponse(events).write(recorder, request)
+
+	// THEN
+	expected := "data: first\n\nid: 2\nevent: update\ndata: line 1\ndata: line 2\n\n"
+	if actual := recorder.Body.String(); actual != expected {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, expected)
+	}
+
+	// Once after the header, then after each event
+	if recorder.flushes != 3 {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.flushes, 3)
+	}
+
+	for name, expected := range map[string]string{
+		"Content-Type": "text/event-stream",
+		"Cache-Control": "no-cache",
+		"Connection": "keep-alive"} {
+		if actual := recorder.Header().Get(name); actual != expected {
+			t.Errorf("%s => actual: '%v', expected: '%v'", name, actual, expected)
+		}
+	}
+}
+
+func TestEventStreamResponse_when_channelClosed(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+	events := make(chan ServerSentEvent, 1)
+	events <- ServerSentEvent{Data: "last"}
+	close(events)
+
+	// WHEN
+	EventStreamResponse(events).write(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	// THEN
+	if actual := recorder.Body.String(); actual != "data: last\n\n" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "data: last\n\n")
+	}
+}
+
+func TestFormatEvent_when_lineBreaks(t *testing.T) {
+	cases := map[string]struct {
+		event ServerSentEvent
+		expected string
+	}{
+		"id": {ServerSentEvent{ID: "1\nevent: admin", Data: "a"}, "id: 1event: admin\ndata: a\n\n"},
+		"id carriage return": {ServerSentEvent{ID: "1\r\rdata: injected", Data: "a"}, "id: 1data: injected\ndata: a\n\n"},
+		"event": {ServerSentEvent{Event: "update\r\ndata: injected", Data: "a"}, "event: updatedata: injected\ndata: a\n\n"},
+		"data carriage return": {ServerSentEvent{Data: "a\revent: admin"}, "data: a\ndata: event: admin\n\n"},
+		"data crlf": {ServerSentEvent{Data: "a\r\nb"}, "data: a\ndata: b\n\n"},
+		"data lf": {ServerSentEvent{Data: "a\nb"}, "data: a\ndata: b\n\n"}}
+
+	for name, c := range cases {
+		// WHEN
+		actual := string(formatEvent(c.event))
+
+		// THEN
+		if actual != c.expected {
+			t.Errorf("%s => actual: '%q', expected: '%q'", name, actual, c.expected)
+		}
+	}
+}
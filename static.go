@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// HTTP RESPONSE (STATIC FILE)
+type StaticFileResponseWriter struct {
+	name string
+	modTime time.Time
+	file http.File
+
+	// Set for a precompressed file, see `openPrecompressedFile()`
+	contentType string
+	contentEncoding string
+}
+
+// `http.ServeContent` handles Content-Type, Content-Length, Range and conditional requests
+func (r *StaticFileResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	defer r.file.Close()
+	if r.contentEncoding != "" {
+		response.Header().Set("Content-Type", r.contentType)
+		response.Header().Set("Content-Encoding", r.contentEncoding)
+	}
+	http.ServeContent(response, request, r.name, r.modTime, r.file)
+}
+
+// Returns the response for a regular file of `root`, or nil if it doesn't exist
+func openStaticFile(root http.FileSystem, name string) HttpResponse {
+	file, err := root.Open(name)
+	if err != nil {
+		return nil
+	}
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		file.Close()
+		return nil
+	}
+
+	return &StaticFileResponseWriter{name: info.Name(), modTime: info.ModTime(), file: file}
+}
+
+// Returns the response for the precompressed `name.gz` of `root` if the client accepts gzip, or nil.
+// The response varies on `Accept-Encoding` as soon as `name.gz` exists.
+func openPrecompressedFile(root http.FileSystem, name string, h *Http) HttpResponse {
+	staticFile, ok := openStaticFile(root, name + ".gz").(*StaticFileResponseWriter)
+	if !ok {
+		return nil
+	}
+
+	h.Response.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsEncoding(h.Request.Header.Get("Accept-Encoding"), "gzip") {
+		staticFile.file.Close()
+		return nil
+	}
+
+	// The Content-Type of the original file, not `application/gzip`
+	staticFile.contentType = mime.TypeByExtension(path.Ext(name))
+	if staticFile.contentType == "" {
+		staticFile.contentType = "application/octet-stream"
+	}
+	staticFile.contentEncoding = "gzip"
+
+	return staticFile
+}
+
+func marshalString(s interface{}) ([]byte, error) {
+	return []byte(s.(string)), nil
+}
+
+// Single Page Application: serves the files of `root` under `urlPrefix`, and falls back to `indexHTML`
+// for every other sub-path so that the client-side router can handle deep links.
+// A precompressed `file.gz` is served instead of `file` to clients accepting gzip.
+// Note: Matches every GET request under `urlPrefix` (`urlPrefix/` excepted, see `Dispatcher.TrailingSlashRedirect`),
+// so it must be registered after your other GET routes.
+func (routes Routes) SPA(urlPrefix string, root http.FileSystem, indexHTML string) Routes {
+	if root == nil {
+		panic("[Routes#SPA] root must not be `nil`")
+	}
+
+	// Ex: `/` => ``, for the catch-all route `/{file...}`
+	prefix := strings.TrimSuffix(urlPrefix, "/")
+
+	handler := func(h *Http) HttpResponse {
+		// Cleaning an absolute path removes any `..` element
+		name := path.Clean("/" + strings.TrimPrefix(h.Request.URL.Path, prefix))
+		if staticFile := openPrecompressedFile(root, name, h); staticFile != nil {
+			return staticFile
+		}
+
+		if staticFile := openStaticFile(root, name); staticFile != nil {
+			return staticFile
+		}
+
+		log.Debug("[Routes#SPA] '%s' => %s", name, os.ErrNotExist)
+		return &ResponseWriter{
+			contentType: "text/html; charset=utf-8",
+			statusCode: http.StatusOK,
+			responseBody: indexHTML,
+			marshal: marshalString}
+	}
+
+	return routes.GET(urlPrefix, handler).GET(prefix + "/{file...}", handler)
+}
@@ -0,0 +1,173 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"testing/fstest"
+)
+
+const mockIndexHTML = `<html><body>index</body></html>`
+
+var mockSPARoot = http.FS(fstest.MapFS{
+	"main.js": &fstest.MapFile{Data: []byte("console.log('main')")},
+	"assets/style.css": &fstest.MapFile{Data: []byte("body {}")},
+	"assets/style.css.gz": &fstest.MapFile{Data: []byte("\x1f\x8bgzipped")},
+})
+
+func TestRoutesSPA_when_assetExists(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().SPA("/app", mockSPARoot, mockIndexHTML), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/app/assets/style.css", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%d', expected: '%d'", recorder.Code, http.StatusOK)
+	}
+
+	if recorder.Body.String() != "body {}" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "body {}")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/css; charset=utf-8" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "text/css; charset=utf-8")
+	}
+}
+
+func TestRoutesSPA_when_deepLinkFallsBackToIndex(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().SPA("/app", mockSPARoot, mockIndexHTML), nil)
+
+	for _, path := range []string{"/app", "/app/users/42", "/app/../main.js/x"} {
+		recorder := httptest.NewRecorder()
+
+		// WHEN
+		dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+		// THEN
+		if recorder.Code != http.StatusOK || recorder.Body.String() != mockIndexHTML {
+			t.Errorf("Path '%s' => actual: '%d %s', expected: '%d %s'", path, recorder.Code, recorder.Body.String(), http.StatusOK, mockIndexHTML)
+		}
+
+		if actual := recorder.Header().Get("Content-Type"); actual != "text/html; charset=utf-8" {
+			t.Errorf("Path '%s' => actual: '%s', expected: '%s'", path, actual, "text/html; charset=utf-8")
+		}
+	}
+}
+
+func TestRoutesSPA_when_trailingSlashRedirect(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().SPA("/app", mockSPARoot, mockIndexHTML), nil)
+	dispatcher.TrailingSlashRedirect = true
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/app/", nil))
+
+	// THEN
+	if recorder.Code != http.StatusMovedPermanently || recorder.Header().Get("Location") != "/app" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Header().Get("Location"), http.StatusMovedPermanently, "/app")
+	}
+}
+
+func TestRoutesSPA_when_listed(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes().SPA("/app", mockSPARoot, mockIndexHTML)
+
+	// WHEN
+	actual := routes.List()
+
+	// THEN
+	if len(actual) != 2 || actual[0].Path != "/app" || actual[1].Path != "/app/{file...}" {
+		t.Errorf("Actual: '%+v', expected: '%s' and '%s'", actual, "/app", "/app/{file...}")
+	}
+}
+
+func TestRoutesSPA_when_otherRouteRegisteredFirst(t *testing.T) {
+	// GIVEN
+	routes := NewRoutes().
+		GET("/api/users", func(h *Http) HttpResponse {
+			return JsonResponse(200, []string{})
+		}).
+		SPA("/app", mockSPARoot, mockIndexHTML)
+	dispatcher := NewDispatcher(routes, nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+
+	// THEN
+	if recorder.Body.String() != "[]" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "[]")
+	}
+}
+
+func TestRoutesSPA_when_precompressedAssetAccepted(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().SPA("/app", mockSPARoot, mockIndexHTML), nil)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/app/assets/style.css", nil)
+	request.Header.Set("Accept-Encoding", "br, gzip")
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Body.String() != "\x1f\x8bgzipped" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "\x1f\x8bgzipped")
+	}
+
+	for name, expected := range map[string]string{
+		"Content-Encoding": "gzip",
+		"Content-Type": "text/css; charset=utf-8",
+		"Vary": "Accept-Encoding"} {
+		if actual := recorder.Header().Get(name); actual != expected {
+			t.Errorf("%s => actual: '%s', expected: '%s'", name, actual, expected)
+		}
+	}
+}
+
+func TestRoutesSPA_when_precompressedAssetNotAccepted(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().SPA("/app", mockSPARoot, mockIndexHTML), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/app/assets/style.css", nil))
+
+	// THEN
+	if recorder.Body.String() != "body {}" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "body {}")
+	}
+
+	if actual := recorder.Header().Get("Content-Encoding"); actual != "" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "")
+	}
+
+	if actual := recorder.Header().Get("Vary"); actual != "Accept-Encoding" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "Accept-Encoding")
+	}
+}
+
+func TestRoutesSPA_when_noPrecompressedAsset(t *testing.T) {
+	// GIVEN
+	dispatcher := NewDispatcher(NewRoutes().SPA("/app", mockSPARoot, mockIndexHTML), nil)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/app/main.js", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, request)
+
+	// THEN
+	if recorder.Body.String() != "console.log('main')" {
+		t.Errorf("Actual: '%s', expected: '%s'", recorder.Body.String(), "console.log('main')")
+	}
+
+	if actual := recorder.Header().Get("Content-Encoding"); actual != "" {
+		t.Errorf("Actual: '%s', expected: '%s'", actual, "")
+	}
+}
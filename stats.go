@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"sort"
+	"time"
+)
+
+// Upper bounds of the buckets of `RouteStat.Histogram`, the last bucket counts the slower requests
+var RouteStatBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// Latencies of a route, returned by `Dispatcher.RouteStats()`
+type RouteStat struct {
+	Count int64
+	Min time.Duration
+	Max time.Duration
+	// Sum of the latencies, see `Avg()`
+	Total time.Duration
+	// Number of requests per bucket of `RouteStatBuckets`, plus one for the slower requests
+	Histogram []int64
+}
+
+func (s RouteStat) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.Total / time.Duration(s.Count)
+}
+
+// Approximated by the upper bound of the bucket holding the percentile (ex: `0.99`), `Max` for the last bucket
+func (s RouteStat) Percentile(percentile float64) time.Duration {
+	rank := int64(percentile * float64(s.Count))
+	var count int64
+	for i, bucketCount := range s.Histogram {
+		count += bucketCount
+		if count > rank || (count == s.Count && count > 0) {
+			if i < len(RouteStatBuckets) {
+				return RouteStatBuckets[i]
+			}
+			return s.Max
+		}
+	}
+
+	return 0
+}
+
+func (s *RouteStat) add(latency time.Duration) {
+	if s.Count == 0 || latency < s.Min {
+		s.Min = latency
+	}
+
+	if latency > s.Max {
+		s.Max = latency
+	}
+
+	s.Count++
+	s.Total += latency
+
+	if s.Histogram == nil {
+		s.Histogram = make([]int64, len(RouteStatBuckets) + 1)
+	}
+	s.Histogram[sort.Search(len(RouteStatBuckets), func(i int) bool { return latency <= RouteStatBuckets[i] })]++
+}
+
+// Key of the route in `Dispatcher.RouteStats()`. Ex: `GET /users/{id}`
+func routeStatKey(httpMethod string, handler CustomHandler) string {
+	path := handler.GetPath()
+	if path == "" {
+		path = handler.GetRegexPath().String()
+	}
+
+	return httpMethod + " " + path
+}
+
+func (dispatcher *Dispatcher) recordRouteStat(httpMethod string, handler CustomHandler, start time.Time) {
+	latency := time.Since(start)
+	key := routeStatKey(httpMethod, handler)
+
+	dispatcher.routeStatsMutex.Lock()
+	defer dispatcher.routeStatsMutex.Unlock()
+
+	if dispatcher.routeStats == nil {
+		dispatcher.routeStats = make(map[string]*RouteStat)
+	}
+
+	routeStat, exists := dispatcher.routeStats[key]
+	if !exists {
+		routeStat = &RouteStat{}
+		dispatcher.routeStats[key] = routeStat
+	}
+	routeStat.add(latency)
+}
+
+// Snapshot of the latencies of the matched routes, by method and registered path (ex: `GET /users/{id}`),
+// see `Dispatcher.CollectRouteStats`
+func (dispatcher *Dispatcher) RouteStats() map[string]RouteStat {
+	dispatcher.routeStatsMutex.Lock()
+	defer dispatcher.routeStatsMutex.Unlock()
+
+	routeStats := make(map[string]RouteStat, len(dispatcher.routeStats))
+	for key, routeStat := range dispatcher.routeStats {
+		snapshot := *routeStat
+		snapshot.Histogram = append([]int64(nil), routeStat.Histogram...)
+		routeStats[key] = snapshot
+	}
+
+	return routeStats
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func TestDispatcherRouteStats_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		time.Sleep(2 * time.Millisecond)
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/users/{id}", handler).GET("/orders", handler), nil)
+	dispatcher.CollectRouteStats = true
+
+	// WHEN
+	for _, calledPath := range []string{"/users/1", "/users/2", "/users/3", "/orders", "/unknown"} {
+		dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, calledPath, nil))
+	}
+	actual := dispatcher.RouteStats()
+
+	// THEN
+	if len(actual) != 2 {
+		t.Errorf("Actual: '%v', expected: '%v'", len(actual), 2)
+	}
+
+	users := actual["GET /users/{id}"]
+	if users.Count != 3 || actual["GET /orders"].Count != 1 {
+		t.Errorf("Actual: '%v %v', expected: '%v %v'", users.Count, actual["GET /orders"].Count, 3, 1)
+	}
+
+	if users.Min < 2 * time.Millisecond || users.Max < users.Min || users.Avg() < users.Min || users.Avg() > users.Max {
+		t.Errorf("Actual: '%v %v %v', expected: min <= avg <= max, min >= 2ms", users.Min, users.Avg(), users.Max)
+	}
+
+	var histogramCount int64
+	for _, bucketCount := range users.Histogram {
+		histogramCount += bucketCount
+	}
+	if histogramCount != 3 {
+		t.Errorf("Actual: '%v', expected: '%v'", histogramCount, 3)
+	}
+}
+
+func TestDispatcherRouteStats_when_disabled(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/orders", handler), nil)
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	// THEN
+	if actual := dispatcher.RouteStats(); len(actual) != 0 {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "no stats")
+	}
+}
+
+func TestRouteStatPercentile_when_nominal(t *testing.T) {
+	// GIVEN
+	var routeStat RouteStat
+	for _, latency := range []time.Duration{time.Millisecond, 3 * time.Millisecond, 3 * time.Millisecond, 2 * time.Second} {
+		routeStat.add(latency)
+	}
+
+	for percentile, expected := range map[float64]time.Duration{0.1: time.Millisecond, 0.5: 5 * time.Millisecond, 1: 2 * time.Second} {
+		// WHEN
+		actual := routeStat.Percentile(percentile)
+
+		// THEN
+		if actual != expected {
+			t.Errorf("Actual: '%v', expected: '%v' (percentile: %v)", actual, expected, percentile)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTP RESPONSE (STREAM)
+// The body is written by `producer` as it goes and flushed after each write: no Content-Length is sent (chunked),
+// and an error of `producer` can't change the status anymore, it is only logged
+type StreamResponseWriter struct {
+	statusCode int
+	contentType string
+	producer func(w io.Writer) error
+}
+
+// Flushes the response after each write, see `writeAndFlush()`
+type flushWriter struct {
+	response http.ResponseWriter
+	timeout time.Duration
+}
+
+func (w *flushWriter) Write(data []byte) (int, error) {
+	return writeAndFlush(w.response, data, w.timeout)
+}
+
+// See `Dispatcher.StreamWriteTimeout`
+func streamWriteTimeout(request *http.Request) time.Duration {
+	if dispatcher, ok := request.Context().Value(dispatcherContextKey).(*Dispatcher); ok {
+		return dispatcher.StreamWriteTimeout
+	}
+
+	return 0
+}
+
+// Writes and flushes `data` before the deadline given by `timeout` (disabled if zero), a stalled client makes the write
+// fail once the deadline is exceeded. The deadline is set on the connection, it's skipped if the writer doesn't give access to it.
+func writeAndFlush(response http.ResponseWriter, data []byte, timeout time.Duration) (int, error) {
+	if timeout > 0 {
+		if err := http.NewResponseController(response).SetWriteDeadline(time.Now().Add(timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return 0, err
+		}
+	}
+
+	n, err := response.Write(data)
+	if err == nil {
+		flush(response)
+	}
+
+	return n, err
+}
+
+// Removes the deadline set by `writeAndFlush()`, so that it doesn't outlive the stream on a kept-alive connection
+func resetWriteDeadline(response http.ResponseWriter, timeout time.Duration) {
+	if timeout > 0 {
+		http.NewResponseController(response).SetWriteDeadline(time.Time{})
+	}
+}
+
+func (r *StreamResponseWriter) write(response http.ResponseWriter, request *http.Request) {
+	// A Content-Type set beforehand takes precedence, like for `ResponseWriter`
+	if response.Header().Get("Content-Type") == "" {
+		response.Header().Set("Content-Type", r.contentType)
+	}
+
+	response.WriteHeader(r.statusCode)
+	flush(response)
+
+	timeout := streamWriteTimeout(request)
+	defer resetWriteDeadline(response, timeout)
+	if err := r.producer(&flushWriter{response: response, timeout: timeout}); err != nil {
+		log.Debug("[StreamResponseWriter#write] producer => %s", err.Error())
+	}
+}
+
+// For large or open-ended bodies (ex: exports, logs), `producer` writes the body. See `StreamResponseWriter`.
+func StreamResponse(statusCode int, contentType string, producer func(w io.Writer) error) HttpResponse {
+	if producer == nil {
+		panic("[StreamResponse] producer must not be `nil`")
+	}
+
+	return &StreamResponseWriter{
+		statusCode: statusCode,
+		contentType: contentType,
+		producer: producer}
+}
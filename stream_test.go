@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"testing"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+)
+
+// Counts the flushes of the embedded recorder
+type flushCountingRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+}
+
+func (r *flushCountingRecorder) Flush() {
+	r.flushes++
+	r.ResponseRecorder.Flush()
+}
+
+func TestStreamResponse_when_nominal(t *testing.T) {
+	// GIVEN
+	recorder := &flushCountingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	producer := func(w io.Writer) error {
+		for i := 1; i <= 3; i++ {
+			if _, err := fmt.Fprintf(w, "chunk%d\n", i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// WHEN
+	StreamResponse(http.StatusOK, "text/plain", producer).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if actual := recorder.Body.String(); actual != "chunk1\nchunk2\nchunk3\n" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "chunk1\nchunk2\nchunk3\n")
+	}
+
+	if actual := recorder.Header().Get("Content-Type"); actual != "text/plain" {
+		t.Errorf("Actual: '%v', expected: '%v'", actual, "text/plain")
+	}
+
+	// Once after the header, then after each chunk
+	if recorder.flushes != 4 {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.flushes, 4)
+	}
+}
+
+func TestStreamResponse_when_error_producer(t *testing.T) {
+	// GIVEN
+	recorder := httptest.NewRecorder()
+	producer := func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errors.New("mock error")
+	}
+
+	// WHEN
+	StreamResponse(http.StatusOK, "text/csv", producer).write(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "partial" {
+		t.Errorf("Actual: '%v %v', expected: '%v %v'", recorder.Code, recorder.Body.String(), http.StatusOK, "partial")
+	}
+}
+
+func TestStreamResponse_when_error_nilProducer(t *testing.T) {
+	// GIVEN
+	defer func() {
+		// THEN
+		if recover() == nil {
+			t.Errorf("Actual: '%v', expected: '%v'", "no panic", "panic")
+		}
+	}()
+
+	// WHEN
+	StreamResponse(http.StatusOK, "text/plain", nil)
+}
+
+// Client which stopped reading: writes block until the write deadline
+type stalledClientWriter struct {
+	*httptest.ResponseRecorder
+	deadline time.Time
+}
+
+func (w *stalledClientWriter) SetWriteDeadline(deadline time.Time) error {
+	w.deadline = deadline
+	return nil
+}
+
+func (w *stalledClientWriter) Write(data []byte) (int, error) {
+	if w.deadline.IsZero() {
+		// Blocking forever would hang the test
+		return 0, errors.New("write without deadline")
+	}
+
+	time.Sleep(time.Until(w.deadline))
+	return 0, os.ErrDeadlineExceeded
+}
+
+func TestDispatcherStreamWriteTimeout_when_stalledClient(t *testing.T) {
+	// GIVEN
+	var producerErr error
+	handler := func(h *Http) HttpResponse {
+		return StreamResponse(http.StatusOK, "text/plain", func(w io.Writer) error {
+			for {
+				if _, producerErr = io.WriteString(w, "chunk"); producerErr != nil {
+					return producerErr
+				}
+			}
+		})
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.StreamWriteTimeout = 10 * time.Millisecond
+	writer := &stalledClientWriter{ResponseRecorder: httptest.NewRecorder()}
+
+	// WHEN
+	dispatcher.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if !errors.Is(producerErr, os.ErrDeadlineExceeded) {
+		t.Errorf("Actual: '%v', expected: '%v'", producerErr, os.ErrDeadlineExceeded)
+	}
+
+	// Reset once the stream is over
+	if !writer.deadline.IsZero() {
+		t.Errorf("Actual: '%v', expected: '%v'", writer.deadline, time.Time{})
+	}
+}
@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Buffers the handler's response, so that it can be discarded if the deadline expires first
+type timeoutWriter struct {
+	mutex sync.Mutex
+	header http.Header
+	body bytes.Buffer
+	statusCode int
+	timedOut bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(statusCode int) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut || w.statusCode != 0 {
+		return
+	}
+	w.statusCode = statusCode
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) written() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.statusCode != 0
+}
+
+// Runs the handler in its own goroutine with a deadline on the request's context.
+// Returns false if the post-filters must be skipped (deadline expired, request body error).
+func (dispatcher *Dispatcher) invokeHandlerWithTimeout(response http.ResponseWriter, request *http.Request, handler CustomHandler, calledPath string, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	defer cancel()
+	request = request.WithContext(ctx)
+
+	writer := &timeoutWriter{header: make(http.Header)}
+	done := make(chan bool, 1)
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			// Only `http.ErrAbortHandler` gets here, other panics are handled by `recoverHandler()`
+			if recovered := recover(); recovered != nil {
+				panicked <- recovered
+			}
+		}()
+		done <- dispatcher.invokeHandler(writer, request, handler, calledPath)
+	}()
+
+	select {
+	case recovered := <-panicked:
+		panic(recovered)
+	case ok := <-done:
+		writer.mutex.Lock()
+		defer writer.mutex.Unlock()
+
+		for name, values := range writer.header {
+			response.Header()[name] = values
+		}
+		if writer.statusCode != 0 {
+			response.WriteHeader(writer.statusCode)
+			response.Write(writer.body.Bytes())
+		}
+		return ok
+	case <-ctx.Done():
+		writer.mutex.Lock()
+		writer.timedOut = true
+		writer.mutex.Unlock()
+
+		log.Debug("[Dispatcher#invokeHandlerWithTimeout] => Method: '%s' | Path: '%s' | Error: %s", request.Method, calledPath, ctx.Err().Error())
+		if ctx.Err() == context.DeadlineExceeded {
+			JsonErrorResponse(http.StatusServiceUnavailable, request, "Handler timeout").write(response, request)
+		}
+		return false
+	}
+}
@@ -0,0 +1,176 @@
+package rest
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func TestWithTimeout_when_deadlineExpired(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		<-h.Request.Context().Done()
+		return TextResponse(http.StatusOK, "too late")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/slow", handler, WithTimeout(10 * time.Millisecond)), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	// THEN
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestWithTimeout_when_nominal(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.Response.Header().Set("X-Fast", "true")
+		return TextResponse(http.StatusOK, "fast")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/fast", handler, WithTimeout(time.Second)), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusOK)
+	}
+
+	if recorder.Body.String() != "fast" {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Body.String(), "fast")
+	}
+
+	if recorder.Header().Get("X-Fast") != "true" {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Header().Get("X-Fast"), "true")
+	}
+}
+
+func TestWithTimeout_when_overridingDispatcherTimeout(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		time.Sleep(20 * time.Millisecond)
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithTimeout(time.Second)), nil)
+	dispatcher.HandlerTimeout = time.Millisecond
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusNoContent)
+	}
+}
+
+func TestDispatcher_when_handlerTimeoutPanic(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		panic("boom")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+	dispatcher.HandlerTimeout = time.Second
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Actual: '%v', expected: '%v'", recorder.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestHttpDeadline_when_timeout(t *testing.T) {
+	// GIVEN
+	var deadline time.Time
+	var ok bool
+	handler := func(h *Http) HttpResponse {
+		deadline, ok = h.Deadline()
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithTimeout(time.Minute)), nil)
+	start := time.Now()
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+	end := time.Now()
+
+	// THEN
+	if !ok {
+		t.Errorf("Actual: '%v', expected: '%v'", ok, true)
+	}
+
+	if deadline.Before(start.Add(time.Minute)) || deadline.After(end.Add(time.Minute)) {
+		t.Errorf("Actual: '%v', expected: '%v'", deadline, "1 minute after the request")
+	}
+}
+
+func TestHttpDeadline_when_noTimeout(t *testing.T) {
+	// GIVEN
+	ok := true
+	handler := func(h *Http) HttpResponse {
+		_, ok = h.Deadline()
+		return NoContentResponse()
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler), nil)
+
+	// WHEN
+	dispatcher.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if ok {
+		t.Errorf("Actual: '%v', expected: '%v'", ok, false)
+	}
+}
+
+func TestWithTimeout_when_writeErrorPartiallyWritten(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.Response.Write([]byte("partial"))
+		h.WriteError(http.StatusInternalServerError, "mock error")
+		return nil
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithTimeout(time.Second)), nil)
+	recorder := httptest.NewRecorder()
+
+	defer func() {
+		// THEN
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("Actual: '%v', expected: '%v'", recovered, http.ErrAbortHandler)
+		}
+
+		if strings.Contains(recorder.Body.String(), "mock error") {
+			t.Errorf("Actual: '%s', expected: no error body", recorder.Body.String())
+		}
+	}()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+}
+
+func TestWithTimeout_when_panicPartiallyWritten(t *testing.T) {
+	// GIVEN
+	handler := func(h *Http) HttpResponse {
+		h.Response.Write([]byte("partial"))
+		panic("mock panic")
+	}
+	dispatcher := NewDispatcher(NewRoutes().GET("/a", handler, WithTimeout(time.Second)), nil)
+	recorder := httptest.NewRecorder()
+
+	// WHEN
+	dispatcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/a", nil))
+
+	// THEN
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "partial" {
+		t.Errorf("Actual: '%d %s', expected: '%d %s'", recorder.Code, recorder.Body.String(), http.StatusOK, "partial")
+	}
+}